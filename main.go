@@ -1,11 +1,28 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/lexers"
@@ -17,27 +34,256 @@ import (
 
 // Config holds values parsed from the lazyclaude config file.
 type Config struct {
-	ResourcesDir string `yaml:"resources_dir"`
-	ClaudeDir    string `yaml:"claude_dir"`
+	ResourcesDir   PathList            `yaml:"resources_dir"` // one or more global stores, highest precedence first
+	ClaudeDir      string              `yaml:"claude_dir"`
+	Strategies     map[string]Strategy `yaml:"strategies"`     // category name → apply strategy
+	Subdirs        map[string]string   `yaml:"subdirs"`        // category name → project subdirectory items are applied into
+	CategoryOrder  []string            `yaml:"category_order"` // categories shown first, in this order
+	Theme          ThemeConfig         `yaml:"theme"`
+	WrapCursor     bool                `yaml:"wrap_cursor"` // j/k wrap around at list edges
+	TreeCount      TreeCount           `yaml:"tree_count"`
+	ConfirmQuit    bool                `yaml:"confirm_quit"`      // ask before quitting with pending changes
+	Descriptions   bool                `yaml:"show_descriptions"` // one-line description under available items
+	ShowPaths      bool                `yaml:"show_paths"`        // list items by their path within the category, extension included
+	PreviewWidth   int                 `yaml:"preview_width"`     // preview column width, percent of the screen
+	NewWithin      string              `yaml:"new_within"`        // items modified this recently are tagged "new"; "0" disables
+	PreviewMax     int                 `yaml:"preview_max_bytes"` // files are previewed up to this size
+	PrimaryDocs    []string            `yaml:"primary_docs"`      // file names previewed for a directory, first found wins
+	AppliedSize    AppliedSize         `yaml:"applied_size"`
+	SortOrder      SortOrder           `yaml:"sort"`
+	Editor         string              `yaml:"editor"`          // command items are opened with; $VISUAL or $EDITOR by default
+	EditorDetach   bool                `yaml:"editor_detach"`   // the editor opens its own window, so don't hand it the terminal
+	TreeIgnore     []string            `yaml:"tree_ignore"`     // gitignore-style patterns hidden from trees
+	Gitignore      bool                `yaml:"tree_gitignore"`  // also hide what a directory item's .gitignore ignores
+	FocusPreview   bool                `yaml:"focus_preview"`   // Tab cycles into the preview, which then scrolls with j/k
+	Confirm        map[string]bool     `yaml:"confirm"`         // action name → ask before running it
+	SelectionColor string              `yaml:"selection_color"` // "#rrggbb" background of the selected row
+	FollowToggle   bool                `yaml:"follow_toggle"`   // after applying or removing, move to the item in its new list
+	HistoryLog     bool                `yaml:"history_log"`     // append applies and removes to claude_dir/.lazyclaude-history.log
+	ConfirmDirs    bool                `yaml:"confirm_dirs"`    // show a directory item's tree and size before applying it
+	ProjectMarker  PathList            `yaml:"project_marker"`  // extra entries that mark a directory as a project, checked first
 }
 
-// loadConfig reads the config file from the lazyclaude config directory.
+// PathList is one path or a list of paths in the config file. As a flag it
+// may be repeated or given comma-separated paths.
+type PathList []string
+
+// UnmarshalYAML accepts either a single path or a sequence of paths.
+func (p *PathList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*p = PathList{node.Value}
+		return nil
+	}
+	var paths []string
+	if err := node.Decode(&paths); err != nil {
+		return err
+	}
+	*p = paths
+	return nil
+}
+
+func (p *PathList) String() string { return strings.Join(*p, ",") }
+
+// Set implements flag.Value.
+func (p *PathList) Set(value string) error {
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			*p = append(*p, path)
+		}
+	}
+	return nil
+}
+
+// TreeCount selects what is counted next to directories in tree views.
+type TreeCount string
+
+const (
+	TreeCountChildren TreeCount = "children" // immediate entries (default)
+	TreeCountFiles    TreeCount = "files"    // files at any depth
+	TreeCountNone     TreeCount = "none"
+)
+
+// AppliedSize is the height of the Applied panel relative to Available.
+type AppliedSize string
+
+const (
+	AppliedEven      AppliedSize = "even"      // half of the column each (default)
+	AppliedSmall     AppliedSize = "small"     // a quarter of the column
+	AppliedCollapsed AppliedSize = "collapsed" // title, count and the current item only
+)
+
+// SortOrder selects how the Available list is ordered.
+type SortOrder string
+
+const (
+	SortByName  SortOrder = "name"  // alphabetical (default)
+	SortByUsage SortOrder = "usage" // most projects applied to first, then by name
+)
+
+// ThemeConfig holds appearance options.
+type ThemeConfig struct {
+	// Background paints the preview with the syntax theme's background color
+	// instead of the terminal's (off by default for transparent terminals).
+	Background bool `yaml:"background"`
+	// Glyphs overrides or extends the category name → Nerd Font glyph map.
+	Glyphs map[string]string `yaml:"glyphs"`
+	// DisableGlyphs hides tab bar glyphs for terminals without a patched font.
+	DisableGlyphs bool `yaml:"disable_glyphs"`
+	// Labels maps category directory names to the names shown for them.
+	Labels map[string]string `yaml:"labels"`
+	// Acronyms are words written in capitals when a category name is
+	// title-cased, in addition to defaultAcronyms.
+	Acronyms []string `yaml:"acronyms"`
+}
+
+// defaultAcronyms are the words capitalized whole in category names.
+var defaultAcronyms = []string{"mcp", "api", "ui", "cli", "sdk"}
+
+// defaultGlyphs are the Nerd Font glyphs shown before well-known categories.
+var defaultGlyphs = map[string]string{
+	"agents":   "\uf544", // robot
+	"commands": "\uf120", // terminal
+	"hooks":    "\uf0e7", // bolt
+	"models":   "\uf2db", // microchip
+	"skills":   "\uf0ad", // wrench
+}
+
+// fallbackGlyph is shown for categories without a configured glyph.
+const fallbackGlyph = "\uf07b" // folder
+
+// glyph returns the tab bar glyph for a category, or "" if glyphs are off.
+func (t ThemeConfig) glyph(category string) string {
+	if t.DisableGlyphs {
+		return ""
+	}
+	if g, ok := t.Glyphs[category]; ok {
+		return g
+	}
+	if g, ok := defaultGlyphs[category]; ok {
+		return g
+	}
+	return fallbackGlyph
+}
+
+// label returns the display name of a category: its configured label, or
+// the directory name title-cased.
+func (t ThemeConfig) label(category string) string {
+	if l, ok := t.Labels[category]; ok {
+		return l
+	}
+	return titleCase(category, slices.Concat(defaultAcronyms, t.Acronyms))
+}
+
+// titleCase splits name on hyphens, underscores and spaces and capitalizes
+// each word, writing acronyms (matched case-insensitively) in full capitals:
+// "mcp-servers" becomes "MCP Servers" and "ui_helpers" "UI Helpers".
+func titleCase(name string, acronyms []string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == ' ' })
+	if len(words) == 0 {
+		return name
+	}
+	for i, word := range words {
+		if slices.ContainsFunc(acronyms, func(a string) bool { return strings.EqualFold(a, word) }) {
+			words[i] = strings.ToUpper(word)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToTitle(r)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// configDir returns the lazyclaude config directory.
 // Resolution order: $LAZYCLAUDE_CONFIG_DIR, $XDG_CONFIG_HOME/lazyclaude, ~/.config/lazyclaude.
-func loadConfig() (*Config, error) {
-	configDir := os.Getenv("LAZYCLAUDE_CONFIG_DIR")
-	if configDir == "" {
-		configHome := os.Getenv("XDG_CONFIG_HOME")
-		if configHome == "" {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				return nil, err
-			}
-			configHome = filepath.Join(home, ".config")
+func configDir() (string, error) {
+	if dir := os.Getenv("LAZYCLAUDE_CONFIG_DIR"); dir != "" {
+		return expandPath(dir), nil
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "lazyclaude"), nil
+}
+
+// setConfigValue sets a top-level key in config.yaml, keeping the rest of
+// the file, comments included. The file is created if it does not exist.
+func setConfigValue(key string, value any) error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "config.yaml")
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parsing config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("parsing config: %s is not a mapping", path)
+	}
+
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return err
+	}
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			valueNode.LineComment = root.Content[i+1].LineComment
+			root.Content[i+1] = &valueNode
+			found = true
+			break
 		}
-		configDir = filepath.Join(configHome, "lazyclaude")
+	}
+	if !found {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &valueNode)
+	}
+
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// expandPath expands $VAR and ${VAR} references and a leading ~ or ~/ to
+// the home directory. ~user forms are left as they are.
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// loadConfig reads the config file from the lazyclaude config directory.
+func loadConfig() (*Config, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := os.ReadFile(filepath.Join(configDir, "config.yaml"))
+	data, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
 	if err != nil {
 		return nil, err
 	}
@@ -47,12 +293,153 @@ func loadConfig() (*Config, error) {
 		return nil, fmt.Errorf("parsing config: %w", err)
 	}
 
-	cfg.ResourcesDir = os.ExpandEnv(cfg.ResourcesDir)
-	cfg.ClaudeDir = os.ExpandEnv(cfg.ClaudeDir)
+	for i, dir := range cfg.ResourcesDir {
+		cfg.ResourcesDir[i] = expandPath(dir)
+	}
+	cfg.ClaudeDir = expandPath(cfg.ClaudeDir)
 
 	return &cfg, nil
 }
 
+// ProjectConfig is the project-local lazyclaude.yaml stored in claude_dir.
+// It lists, per category, the items that should be applied to the project.
+type ProjectConfig struct {
+	Applied map[string][]string `yaml:"applied"`
+}
+
+const projectConfigName = "lazyclaude.yaml"
+
+// loadProjectConfig reads claudeDir/lazyclaude.yaml. A missing file is not an
+// error; it returns nil.
+func loadProjectConfig(claudeDir string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(filepath.Join(claudeDir, projectConfigName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pc ProjectConfig
+	if err := yaml.Unmarshal(data, &pc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", projectConfigName, err)
+	}
+	return &pc, nil
+}
+
+// writeProjectConfig saves pc to claudeDir/lazyclaude.yaml.
+func writeProjectConfig(claudeDir string, pc *ProjectConfig) error {
+	data, err := yaml.Marshal(pc)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(claudeDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(claudeDir, projectConfigName), data, 0644)
+}
+
+// loadFavorites reads favorites.json from the config directory, mapping
+// category name to starred item names. A missing file yields an empty map.
+func loadFavorites() (map[string][]string, error) {
+	favorites := map[string][]string{}
+	dir, err := configDir()
+	if err != nil {
+		return favorites, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "favorites.json"))
+	if os.IsNotExist(err) {
+		return favorites, nil
+	}
+	if err != nil {
+		return favorites, err
+	}
+	if err := json.Unmarshal(data, &favorites); err != nil {
+		return favorites, fmt.Errorf("parsing favorites.json: %w", err)
+	}
+	return favorites, nil
+}
+
+// saveFavorites writes favorites to favorites.json in the config directory.
+func saveFavorites(favorites map[string][]string) error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(favorites, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "favorites.json"), append(data, '\n'), 0644)
+}
+
+// usageIndex maps category name → item name → the project directories the
+// item has been applied to, across every project lazyclaude is used in.
+type usageIndex map[string]map[string][]string
+
+// count returns how many projects the named item of category has been
+// applied to.
+func (u usageIndex) count(category, name string) int {
+	return len(u[category][name])
+}
+
+// loadUsage reads usage.json from the config directory. A missing file
+// yields an empty index.
+func loadUsage() (usageIndex, error) {
+	usage := usageIndex{}
+	dir, err := configDir()
+	if err != nil {
+		return usage, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "usage.json"))
+	if os.IsNotExist(err) {
+		return usage, nil
+	}
+	if err != nil {
+		return usage, err
+	}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return usage, fmt.Errorf("parsing usage.json: %w", err)
+	}
+	return usage, nil
+}
+
+// recordUsage adds cat's project directory to each item's entry in
+// usage.json and returns the updated index. The file is re-read first so
+// concurrent sessions in other projects don't overwrite each other.
+func recordUsage(cat Category, items ...Item) (usageIndex, error) {
+	usage, err := loadUsage()
+	if err != nil {
+		return usage, err
+	}
+	byItem := usage[cat.Name]
+	if byItem == nil {
+		byItem = map[string][]string{}
+		usage[cat.Name] = byItem
+	}
+	for _, item := range items {
+		if !slices.Contains(byItem[item.Name], cat.ProjectDir) {
+			byItem[item.Name] = append(byItem[item.Name], cat.ProjectDir)
+		}
+	}
+
+	dir, err := configDir()
+	if err != nil {
+		return usage, err
+	}
+	data, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return usage, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return usage, err
+	}
+	return usage, os.WriteFile(filepath.Join(dir, "usage.json"), append(data, '\n'), 0644)
+}
+
 func init() {
 	tview.Borders.Horizontal = '─'
 	tview.Borders.Vertical = '│'
@@ -62,18 +449,77 @@ func init() {
 	tview.Borders.BottomRight = '╯'
 }
 
-// Category represents a subdirectory in the global store (e.g. agents, skills).
+// Strategy controls how items of a category are applied to the project.
+type Strategy string
+
+const (
+	StrategySymlink  Strategy = "symlink"  // link the item itself (default)
+	StrategyCopy     Strategy = "copy"     // copy the item into the project
+	StrategyMerge    Strategy = "merge"    // link a directory's entries into a same-named project directory
+	StrategyHardlink Strategy = "hardlink" // hard-link the item's files into the project
+)
+
+// Category represents a subdirectory in the global stores (e.g. agents, skills).
 type Category struct {
-	Name       string // directory name, e.g. "agents"
-	GlobalDir  string // ~/.config/claude/agents
-	ProjectDir string // /project/.claude/agents
+	Name       string        // directory name, e.g. "agents"
+	GlobalDirs []CategoryDir // the category's directory in each store that has it, in store order
+	ProjectDir string        // /project/.claude/agents, plus ProjectSubdir
+	Strategy   Strategy      // how items are applied
+
+	// ProjectSubdir is a path under the category's project directory that
+	// items are applied into, e.g. "custom" for .claude/commands/custom.
+	ProjectSubdir string
+
+	// Root marks the rootCategory pseudo-category: the files at the top of
+	// each store, applied directly into claude_dir.
+	Root bool
+}
+
+// claudeDir returns the claude_dir that cat's project directory lies in.
+func (cat Category) claudeDir() string {
+	if cat.Root {
+		return cat.ProjectDir
+	}
+	dir := filepath.Dir(cat.ProjectDir)
+	if cat.ProjectSubdir != "" {
+		for range strings.Split(filepath.ToSlash(filepath.Clean(cat.ProjectSubdir)), "/") {
+			dir = filepath.Dir(dir)
+		}
+	}
+	return dir
+}
+
+// rootCategory names the pseudo-category of top-level store files such as
+// settings.json or CLAUDE.md. A real "root" directory takes precedence.
+const rootCategory = "root"
+
+// CategoryDir is one global store's directory for a category.
+type CategoryDir struct {
+	Path   string // ~/.config/claude/agents
+	Origin string // label of the store; empty when only one store is configured
 }
 
 // Item represents a single agent, skill, or other resource.
 type Item struct {
-	Name       string
-	IsDir      bool
-	GlobalPath string
+	Name        string
+	DisplayPath string // path within the category directory, shown with show_paths
+	IsDir       bool
+	GlobalPath  string
+	RealPath    string // GlobalPath with symlinks resolved
+	IsLink      bool   // the global store entry is itself a symlink
+	ModTime     time.Time
+	Origin      string // label of the store the item comes from, if there are several
+	LinkName    string // project entry name when applied under a different name
+	Warning     string // set for applied items whose project link points elsewhere
+	GitState    string // "gitignored" or "tracked" for applied items in a git project
+}
+
+// linkName returns the name of the item's entry in the project directory.
+func (item Item) linkName() string {
+	if item.LinkName != "" {
+		return item.LinkName
+	}
+	return item.Name
 }
 
 // DisplayName returns the item name without file extension for non-directory items.
@@ -84,6 +530,26 @@ func (item Item) DisplayName() string {
 	return strings.TrimSuffix(item.Name, filepath.Ext(item.Name))
 }
 
+// ActionKind identifies a mutating operation that can be undone.
+type ActionKind int
+
+const (
+	ActionApply ActionKind = iota
+	ActionRemove
+)
+
+// Action records the last apply/remove so it can be reversed. Bulk
+// operations record every item they touched.
+type Action struct {
+	Kind     ActionKind
+	Category Category
+	Items    []Item
+
+	// WholeCategory marks linking or unlinking the category as a single
+	// directory (see toggleCategoryLink); Items is then empty.
+	WholeCategory bool
+}
+
 // App holds all application state.
 type App struct {
 	app             *tview.Application
@@ -91,25 +557,177 @@ type App struct {
 	panels          []tview.Primitive
 	currentPanelIdx int
 
+	rootFlex      *tview.Flex
+	mainFlex      *tview.Flex
 	availableList *tview.List
 	appliedList   *tview.List
+	previewFlex   *tview.Flex // preview column: optional global side, then previewView
 	previewView   *tview.TextView
+	previewGlobal *tview.TextView // global source shown beside the project version
+	infoBar       *tview.TextView // link target of the selected applied item
 	statusBar     *tview.TextView
 	tabBar        *tview.TextView
 
 	categories     []Category
 	activeTabIdx   int
 	availableItems []Item
+	availableRows  []int // Available list row → availableItems index; -1 for group headers
+	groupMode      groupMode
+	ascending      bool // item sort direction; toggled with S
 	appliedItems   []Item
+	appliedCounts  []int // applied item count per category, indexed like categories
+
+	globalRoots   []string // global stores, highest precedence first
+	claudeDir     string
+	projectStore  string // the detected project store, or ""; also in globalRoots
+	readOnly      bool   // no action modifies the filesystem, not even pruneBrokenLinks
+	noColor       bool   // draw without colors, for NO_COLOR and --no-color
+	strategies    map[string]Strategy
+	subdirs       map[string]string
+	categoryOrder []string // category names pinned to the front of the tabs
+	theme         ThemeConfig
+	wrapCursor    bool
+	treeCount     TreeCount
+	appliedSize   AppliedSize
+	sortOrder     SortOrder
+	usage         usageIndex // projects each item has been applied to, for SortByUsage
+	editor        string
+	editorDetach  bool
+	treeIgnore    []string // patterns hidden from trees
+	gitignore     bool     // honor .gitignore at a directory item's root in trees
+	primaryDocs   []string // file names, in order, that stand for a directory item
+	historyLog    bool     // logOperation records applies and removes
+
+	confirmQuit    bool
+	confirmActions map[string]bool           // confirm: per-action ask-first overrides
+	showDescs      bool                      // description line under each available item
+	showPaths      bool                      // lists show DisplayPath instead of DisplayName
+	descCache      map[string]descCacheEntry // item descriptions by path
+	pending        []pendingChange           // in-session changes not yet saved or finished
+	pendingSeq     int                       // last pendingChange id handed out
+
+	previewHeader  string // tagged title line of a content preview
+	previewContent string // raw text being previewed; empty for tree previews
+	previewLang    string
+	previewPathKey string                    // path of the item currently previewed
+	scrollMemory   map[string]scrollPosition // preview offsets by path, for this session
+	searchQuery    string
+	searchMatches  int
+	searchIdx      int
+
+	scanGen       int  // incremented per refresh; stale async scans are dropped
+	scanning      bool // an async scan is in flight and the lists are cleared
+	lastAction    *Action
+	history       []historyEntry // applies and removes this session, oldest first
+	projectConfig *ProjectConfig
+	favorites     map[string][]string // category name → starred item names
+	keymap        map[keyID]func()
+	keyActions    map[keyID]string // action name bound to each key
+
+	count       int    // pending vim-style numeric prefix; 0 when none
+	countAction func() // action of a lone bound digit, run if no motion follows
+	countGen    int    // invalidates the timer of an earlier prefix
+
+	jumpLabels map[int]int    // jump overlay: label → list row; nil when not shown
+	jumpTyped  string         // digits typed so far in the jump overlay
+	jumpTexts  map[int]string // main text of each labeled row, restored afterwards
+
+	compact         bool          // single-column layout for narrow terminals
+	splitPreview    bool          // show global and project versions of applied items side by side
+	previewHidden   bool          // preview column hidden to give the lists the full width
+	previewFocus    bool          // focus_preview: the preview column can take focus
+	previewFocused  bool          // the preview column has focus; currentPanelIdx is the list it previews
+	previewWidth    int           // preview column width in percent, within min/maxPreviewWidth
+	newWithin       time.Duration // recency window for the "new" tag; 0 disables it
+	previewMax      int           // bytes of a file shown in previews
+	selectionColor  tcell.Color   // background of the selected row in lists
+	followToggle    bool          // follow_toggle: the cursor follows a toggled item to its new list
+	confirmDirs     bool          // confirm_dirs: ask before applying a directory item
+	projectMarkers  []string      // entries that mark a sibling directory as a project, in order
+	leftFlex        *tview.Flex
+	previewOpen     bool // preview shown full-screen in compact mode
+	helpOpen        bool
+	treeOpen        bool
+	treeView        *tview.TreeView
+	treePreview     *tview.TextView // preview of the file under the tree cursor
+	treeItem        *Item
+	treeDepth       int
+	treeFilter      *treeIgnore // entries hidden from the tree modal
+	promptOpen      bool
+	pickerOpen      bool
+	projectsOpen    bool // sibling project multi-select for applying elsewhere
+	historyOpen     bool
+	chainOpen       bool // symlink resolution chain of an applied item
+	welcomeOpen     bool // first-run introduction
+	pathsOpen       bool // resolved store and project paths
+	reviewOpen      bool // starred items' contents concatenated for review
+	paletteOpen     bool
+	zoomOpen        bool // preview expanded into a near-fullscreen modal
+	zoomLineNumbers bool
+	zoomView        *tview.TextView
+	zoomFrame       tview.Primitive // zoom page, refit when the terminal is resized
+	treeFrame       tview.Primitive // tree page, refit when the terminal is resized
+	outlineOpen     bool
+	outlineFrame    tview.Primitive // outline page, refit when the terminal is resized
+
+	screenWidth, screenHeight int
+	confirmOpen               bool
+	confirmAction             func()
+}
+
+// defaultNewWithin is how recently an item must have changed to be tagged
+// "new" when new_within is not configured.
+const defaultNewWithin = 24 * time.Hour
+
+// defaultPreviewMax is how much of a file previews show when
+// preview_max_bytes is not configured.
+const defaultPreviewMax = 100 * 1024
 
-	globalRoot string
-	claudeDir  string
+// defaultSelectionColor is the selected row's background unless
+// selection_color is set.
+var defaultSelectionColor = tcell.NewRGBColor(106, 159, 181)
 
-	helpOpen bool
-	treeOpen bool
+// parseHexColor parses a "#rrggbb" (or "rrggbb") color.
+func parseHexColor(hex string) (tcell.Color, error) {
+	digits := strings.TrimPrefix(hex, "#")
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) != 6 {
+		return tcell.ColorDefault, fmt.Errorf("%q is not a #rrggbb color", hex)
+	}
+	return tcell.NewHexColor(int32(value)), nil
+}
+
+// projectStoreName is the directory a project can check in to share items
+// with everyone working on it. It is merged in as an extra store.
+const projectStoreName = ".claude-store"
+
+// defaultPrimaryDocs is used when primary_docs is not set.
+var defaultPrimaryDocs = []string{"SKILL.md"}
+
+// primaryDoc returns the path of dir's primary doc, the first of docs
+// present in it, or "" if it has none.
+func primaryDoc(dir string, docs []string) string {
+	for _, name := range docs {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
 }
 
 func main() {
+	var resourcesDirs PathList
+	var readOnly, noColor bool
+	flag.BoolVar(&readOnly, "read-only", false, "browse and preview without modifying anything")
+	flag.Var(&resourcesDirs, "resources-dir", "global store to browse; repeat or comma-separate for several (overrides resources_dir)")
+	flag.BoolVar(&noColor, "no-color", os.Getenv("NO_COLOR") != "", "disable colors and syntax highlighting (also set by NO_COLOR)")
+	flag.Usage = usage
+	flag.Parse()
+	if noColor {
+		tview.Styles.PrimitiveBackgroundColor = tcell.ColorDefault
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -117,663 +735,6400 @@ func main() {
 	}
 
 	a := &App{
-		globalRoot: filepath.Join(home, ".config", "claude"),
+		globalRoots: []string{filepath.Join(home, ".config", "claude")},
+		readOnly:    readOnly,
+		noColor:     noColor,
+		ascending:   true,
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		cfg = &Config{} // every setting at its default
+	}
+	if len(cfg.ResourcesDir) > 0 {
+		a.globalRoots = cfg.ResourcesDir
+	}
+	if cfg.ClaudeDir != "" {
+		a.claudeDir = cfg.ClaudeDir
+	}
+	if err := a.applyConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 
-	if cfg, err := loadConfig(); err == nil {
-		if cfg.ResourcesDir != "" {
-			a.globalRoot = cfg.ResourcesDir
+	if len(resourcesDirs) > 0 {
+		for i, dir := range resourcesDirs {
+			resourcesDirs[i] = expandPath(dir)
 		}
-		if cfg.ClaudeDir != "" {
-			a.claudeDir = cfg.ClaudeDir
+		a.globalRoots = resourcesDirs
+	}
+
+	if a.claudeDir != "" {
+		store := filepath.Join(filepath.Dir(a.claudeDir), projectStoreName)
+		if info, err := os.Stat(store); err == nil && info.IsDir() && !slices.Contains(a.globalRoots, store) {
+			// Personal stores keep precedence; the project's comes last.
+			a.globalRoots = append(a.globalRoots, store)
+			a.projectStore = store
 		}
 	}
 
+	if flag.Arg(0) == "doctor" {
+		// The doctor reports setup problems instead of stopping at the first.
+		os.Exit(a.runDoctor(flag.Args()[1:]))
+	}
+
 	if a.claudeDir == "" {
 		fmt.Fprintf(os.Stderr, "Error: claude_dir not set in config\n")
 		os.Exit(1)
 	}
 
+	// A first run has nothing in any store and hasn't been welcomed yet. A
+	// missing first store is offered for creation whenever it is missing.
+	firstRun := flag.NArg() == 0 && !setupComplete() && storesEmpty(a.globalRoots)
+	_, statErr := os.Stat(a.globalRoots[0])
+	if (os.IsNotExist(statErr) && flag.NArg() == 0 || firstRun) && !a.readOnly {
+		if err := ensureGlobalRoot(a.globalRoots[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	if err := a.loadCategories(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading categories: %v\n", err)
 		os.Exit(1)
 	}
 
 	if len(a.categories) == 0 {
-		fmt.Fprintf(os.Stderr, "No categories found in %s\n", a.globalRoot)
+		fmt.Fprintf(os.Stderr, "No categories found in %s\n", strings.Join(a.globalRoots, ", "))
 		os.Exit(1)
 	}
 
+	if flag.NArg() > 0 {
+		os.Exit(a.runCommand(flag.Arg(0), flag.Args()[1:]))
+	}
+
+	projectConfig, projectConfigErr := loadProjectConfig(a.claudeDir)
+	a.projectConfig = projectConfig
+	favorites, favoritesErr := loadFavorites()
+	a.favorites = favorites
+	a.usage, _ = loadUsage() // only orders the list; a bad file sorts by name
+
+	keyOverrides, keysErr := loadKeyOverrides()
+
 	a.setupUI()
+	keyWarnings := a.buildKeymap(keyOverrides)
 	a.refreshAll()
 
+	if keysErr != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %v", keysErr))
+	} else if len(keyWarnings) > 0 {
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]keys.yaml: %s[-]", tview.Escape(strings.Join(keyWarnings, "; "))))
+	} else if projectConfigErr != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %v", projectConfigErr))
+	} else if favoritesErr != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %v", favoritesErr))
+	} else if missing := a.missingFromProjectConfig(); missing > 0 {
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]%d items in %s are not applied — %s to sync[-]", missing, projectConfigName, a.pressKey("syncProjectConfig")))
+	}
+
+	if firstRun {
+		a.showWelcome()
+	}
+
 	if err := a.app.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-// loadCategories scans the global store for subdirectories.
-func (a *App) loadCategories() error {
-	entries, err := os.ReadDir(a.globalRoot)
-	if err != nil {
-		return err
+// applyConfig copies the settings in cfg that can change while running into
+// a, using the defaults for those left unset. Values are checked before
+// anything is changed, so on error a keeps its current settings.
+// resources_dir and claude_dir are only read at startup, by main.
+func (a *App) applyConfig(cfg *Config) error {
+	for name, s := range cfg.Strategies {
+		if !validStrategy(s) {
+			return fmt.Errorf("strategies: unknown apply strategy %q for category %q", s, name)
+		}
 	}
-
-	a.categories = nil
-	for _, entry := range entries {
-		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
-			continue
+	for name, subdir := range cfg.Subdirs {
+		if subdir != "" && !filepath.IsLocal(subdir) {
+			return fmt.Errorf("subdirs: %q for category %q must be a relative path inside it", subdir, name)
 		}
-		a.categories = append(a.categories, Category{
-			Name:       entry.Name(),
-			GlobalDir:  filepath.Join(a.globalRoot, entry.Name()),
-			ProjectDir: filepath.Join(a.claudeDir, entry.Name()),
-		})
+	}
+	for name := range cfg.Confirm {
+		if _, ok := a.actions()[name]; !ok {
+			return fmt.Errorf("confirm: unknown action %q", name)
+		}
+	}
+	newWithin := defaultNewWithin
+	if cfg.NewWithin != "" {
+		d, err := time.ParseDuration(cfg.NewWithin)
+		if err != nil {
+			return fmt.Errorf("new_within: %w", err)
+		}
+		newWithin = d
+	}
+	for _, marker := range cfg.ProjectMarker {
+		if !filepath.IsLocal(marker) {
+			return fmt.Errorf("project_marker: %q must be a relative path inside a project", marker)
+		}
+	}
+	selectionColor := defaultSelectionColor
+	if cfg.SelectionColor != "" {
+		color, err := parseHexColor(cfg.SelectionColor)
+		if err != nil {
+			return fmt.Errorf("selection_color: %w", err)
+		}
+		selectionColor = color
 	}
 
-	sort.Slice(a.categories, func(i, j int) bool {
-		return a.categories[i].Name < a.categories[j].Name
-	})
-
+	a.strategies = cfg.Strategies
+	a.subdirs = cfg.Subdirs
+	a.categoryOrder = cfg.CategoryOrder
+	a.theme = cfg.Theme
+	a.wrapCursor = cfg.WrapCursor
+	a.treeCount = cfg.TreeCount
+	a.appliedSize = cfg.AppliedSize
+	a.sortOrder = cfg.SortOrder
+	a.editor = cfg.Editor
+	a.editorDetach = cfg.EditorDetach
+	a.treeIgnore = defaultTreeIgnore
+	if cfg.TreeIgnore != nil {
+		a.treeIgnore = cfg.TreeIgnore
+	}
+	a.gitignore = cfg.Gitignore
+	a.previewFocus = cfg.FocusPreview
+	a.followToggle = cfg.FollowToggle
+	a.confirmDirs = cfg.ConfirmDirs
+	a.projectMarkers = append(slices.Clone([]string(cfg.ProjectMarker)), defaultProjectMarkers...)
+	a.confirmActions = cfg.Confirm
+	a.confirmQuit = cfg.ConfirmQuit
+	a.showDescs = cfg.Descriptions
+	a.showPaths = cfg.ShowPaths
+	a.previewWidth = cfg.PreviewWidth
+	a.newWithin = newWithin
+	a.previewMax = defaultPreviewMax
+	if cfg.PreviewMax > 0 {
+		a.previewMax = cfg.PreviewMax
+	}
+	a.selectionColor = selectionColor
+	a.primaryDocs = defaultPrimaryDocs
+	if len(cfg.PrimaryDocs) > 0 {
+		a.primaryDocs = cfg.PrimaryDocs
+	}
+	a.historyLog = cfg.HistoryLog
 	return nil
 }
 
-// loadItems scans a category and partitions into available and applied.
-func (a *App) loadItems() {
-	cat := a.categories[a.activeTabIdx]
-	a.availableItems = nil
-	a.appliedItems = nil
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: lazyclaude [flags] [command]
 
-	entries, err := os.ReadDir(cat.GlobalDir)
-	if err != nil {
-		return
-	}
+Without a command, lazyclaude starts the interactive UI.
 
-	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), ".") {
-			continue
-		}
-		item := Item{
-			Name:       entry.Name(),
-			IsDir:      entry.IsDir(),
-			GlobalPath: filepath.Join(cat.GlobalDir, entry.Name()),
-		}
+Commands:
+  status [--json]                    Print the applied items of every category
+  apply <category> <item>...         Apply items to the project
+  remove <category> <item>...        Remove applied items from the project
+  doctor                             Check the stores and project links for problems
 
-		projectPath := filepath.Join(cat.ProjectDir, entry.Name())
-		if isAppliedSymlink(projectPath, item.GlobalPath) {
-			a.appliedItems = append(a.appliedItems, item)
-		} else {
-			a.availableItems = append(a.availableItems, item)
-		}
-	}
+Flags:
+`)
+	flag.PrintDefaults()
+}
 
-	sort.Slice(a.availableItems, func(i, j int) bool {
-		return a.availableItems[i].Name < a.availableItems[j].Name
-	})
-	sort.Slice(a.appliedItems, func(i, j int) bool {
-		return a.appliedItems[i].Name < a.appliedItems[j].Name
-	})
+// --- Subcommands ---
+
+// runCommand runs a non-interactive subcommand and returns its exit code.
+func (a *App) runCommand(name string, args []string) int {
+	switch name {
+	case "status":
+		return a.runStatus(args)
+	case "apply", "remove":
+		return a.runToggle(name, args)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n", name)
+		usage()
+		return 2
+	}
 }
 
-// isAppliedSymlink checks if projectPath is a symlink pointing to globalPath.
-func isAppliedSymlink(projectPath, globalPath string) bool {
+// runDoctor checks that the stores are readable, lists the categories
+// found, and looks for broken project links and links leading outside the
+// stores. It never modifies anything, and exits 1 if it found a problem.
+func (a *App) runDoctor(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: doctor takes no arguments\n")
+		return 2
+	}
+	a.readOnly = true
+
+	problems := 0
+	bad := func(format string, args ...any) {
+		problems++
+		fmt.Printf("  ✗ "+format+"\n", args...)
+	}
+	good := func(format string, args ...any) {
+		fmt.Printf("  ✓ "+format+"\n", args...)
+	}
+
+	fmt.Println("Stores")
+	var roots []string // canonical paths of the readable stores
+	for _, root := range a.globalRoots {
+		if _, err := os.ReadDir(root); err != nil {
+			bad("%v", err)
+			continue
+		}
+		roots = append(roots, canonicalPath(root))
+		good("%s", root)
+	}
+
+	fmt.Println("Project")
+	switch _, err := os.Stat(a.claudeDir); {
+	case a.claudeDir == "":
+		bad("claude_dir is not set in the config")
+	case os.IsNotExist(err):
+		good("%s (not created yet)", a.claudeDir)
+	case err != nil:
+		bad("%v", err)
+	default:
+		good("%s", a.claudeDir)
+	}
+
+	fmt.Println("Categories")
+	if len(roots) < len(a.globalRoots) {
+		fmt.Println("  - skipped: a store could not be read")
+	} else if err := a.loadCategories(); err != nil {
+		bad("%v", err)
+	} else if len(a.categories) == 0 {
+		bad("no categories found in %s", strings.Join(a.globalRoots, ", "))
+	}
+	for _, cat := range a.categories {
+		if linkedCategory(cat) {
+			good("%s: linked as directory", cat.Name)
+			continue
+		}
+		available, applied := scanCategory(cat, a.primaryDocs)
+		good("%s: %d %s, %d applied", cat.Name, len(available)+len(applied), plural(len(available)+len(applied), "item", "items"), len(applied))
+	}
+
+	if a.claudeDir != "" && len(a.categories) > 0 {
+		fmt.Println("Project links")
+		before := problems
+		for _, cat := range a.categories {
+			if linkedCategory(cat) {
+				continue
+			}
+			depth := 0
+			if cat.Strategy == StrategyMerge {
+				depth = 1 // merged directories hold the links one level down
+			}
+			for _, link := range symlinksIn(cat.ProjectDir, depth) {
+				if _, err := os.Stat(link); err != nil {
+					target, _ := os.Readlink(link)
+					bad("%s is broken (links to %s)", link, target)
+				} else if !insideAny(canonicalPath(link), roots) {
+					bad("%s links outside the stores, to %s", link, canonicalPath(link))
+				}
+			}
+		}
+		if problems == before {
+			good("no broken links or links outside the stores")
+		}
+	}
+
+	fmt.Println()
+	if problems > 0 {
+		fmt.Printf("%d %s found\n", problems, plural(problems, "problem", "problems"))
+		return 1
+	}
+	fmt.Println("No problems found")
+	return 0
+}
+
+// symlinksIn returns the paths of the symlinks in dir, looking into real
+// subdirectories up to depth levels deep.
+func symlinksIn(dir string, depth int) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var links []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.Type()&os.ModeSymlink != 0:
+			links = append(links, path)
+		case entry.IsDir() && depth > 0:
+			links = append(links, symlinksIn(path, depth-1)...)
+		}
+	}
+	return links
+}
+
+// insideAny reports whether path is one of roots or lies beneath one.
+func insideAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if rel, err := filepath.Rel(root, path); err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusItem is the JSON form of an item in `lazyclaude status --json`.
+type StatusItem struct {
+	Name     string `json:"name"`
+	LinkName string `json:"link_name,omitempty"`
+	Origin   string `json:"origin,omitempty"`
+	Warning  string `json:"warning,omitempty"`
+}
+
+// StatusCategory is the JSON form of a category in `lazyclaude status --json`.
+type StatusCategory struct {
+	Name      string       `json:"name"`
+	Strategy  Strategy     `json:"strategy"`
+	Linked    bool         `json:"linked,omitempty"` // the project directory links to the whole category
+	Applied   []StatusItem `json:"applied"`
+	Available []string     `json:"available"`
+}
+
+// Status is the document printed by `lazyclaude status --json`.
+type Status struct {
+	ResourcesDir  string           `json:"resources_dir"`            // highest-precedence store
+	ResourcesDirs []string         `json:"resources_dirs,omitempty"` // every store, when there are several
+	ClaudeDir     string           `json:"claude_dir"`
+	Categories    []StatusCategory `json:"categories"`
+}
+
+// runStatus prints which items are applied in each category, as text or JSON.
+// It never modifies the project.
+func (a *App) runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print machine-readable JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	a.readOnly = true
+
+	status := Status{ResourcesDir: a.globalRoots[0], ClaudeDir: a.claudeDir}
+	if len(a.globalRoots) > 1 {
+		status.ResourcesDirs = a.globalRoots
+	}
+	for _, cat := range a.categories {
+		for _, dir := range cat.GlobalDirs {
+			if _, err := os.ReadDir(dir.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "Error scanning %s: %v\n", cat.Name, err)
+				return 1
+			}
+		}
+		available, applied := scanCategory(cat, a.primaryDocs)
+		sc := StatusCategory{
+			Name:      cat.Name,
+			Strategy:  cat.Strategy,
+			Linked:    linkedCategory(cat),
+			Applied:   []StatusItem{},
+			Available: []string{},
+		}
+		for _, item := range applied {
+			sc.Applied = append(sc.Applied, StatusItem{Name: item.Name, LinkName: item.LinkName, Origin: item.Origin, Warning: item.Warning})
+		}
+		for _, item := range available {
+			sc.Available = append(sc.Available, item.Name)
+		}
+		status.Categories = append(status.Categories, sc)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(status); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	for _, sc := range status.Categories {
+		if sc.Linked {
+			fmt.Printf("%s (linked as directory)\n", sc.Name)
+			continue
+		}
+		fmt.Printf("%s (%d/%d applied)\n", sc.Name, len(sc.Applied), len(sc.Applied)+len(sc.Available))
+		for _, item := range sc.Applied {
+			line := "  + " + item.Name
+			if item.LinkName != "" {
+				line += " as " + item.LinkName
+			}
+			if item.Warning != "" {
+				line += " (" + item.Warning + ")"
+			}
+			fmt.Println(line)
+		}
+	}
+	return 0
+}
+
+// runToggle applies or removes the named items of a category headlessly,
+// using the same logic as the UI.
+func (a *App) runToggle(name string, args []string) int {
+	if len(args) < 2 {
+		fmt.Fprintf(os.Stderr, "Usage: lazyclaude %s <category> <item>...\n", name)
+		return 2
+	}
+	if a.readOnly {
+		fmt.Fprintf(os.Stderr, "Error: %s is not allowed in read-only mode\n", name)
+		return 1
+	}
+
+	cat, ok := a.findCategory(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown category %q\n", args[0])
+		return 1
+	}
+
+	available, applied := scanCategory(cat, a.primaryDocs)
+	candidates, fn, verb := available, a.linkItem, "Applied"
+	if name == "remove" {
+		candidates, fn, verb = applied, a.unlinkItem, "Removed"
+	}
+
+	code := 0
+	for _, itemName := range args[1:] {
+		item, ok := findItem(candidates, itemName)
+		if !ok {
+			if _, isApplied := findItem(applied, itemName); isApplied && name == "apply" {
+				fmt.Printf("%s/%s is already applied\n", cat.Name, itemName)
+				continue
+			}
+			if _, isAvailable := findItem(available, itemName); isAvailable && name == "remove" {
+				fmt.Printf("%s/%s is not applied\n", cat.Name, itemName)
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "Error: no item %q in %s\n", itemName, cat.Name)
+			code = 1
+			continue
+		}
+		if err := fn(cat, item); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s/%s: %s\n", cat.Name, item.Name, describeFSError(err))
+			code = 1
+			continue
+		}
+		if name == "apply" {
+			recordUsage(cat, item)
+		}
+		fmt.Printf("%s %s/%s\n", verb, cat.Name, item.Name)
+	}
+	return code
+}
+
+// findCategory looks up a category by directory name.
+func (a *App) findCategory(name string) (Category, bool) {
+	for _, cat := range a.categories {
+		if cat.Name == name {
+			return cat, true
+		}
+	}
+	return Category{}, false
+}
+
+// findItem looks up an item by file name or display name.
+func findItem(items []Item, name string) (Item, bool) {
+	for _, item := range items {
+		if item.Name == name || item.DisplayName() == name {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// defaultCategories are created when bootstrapping a new global store.
+var defaultCategories = []string{"agents", "commands", "skills"}
+
+// storesEmpty reports whether every store is missing or has no visible
+// entries, as on a first run.
+func storesEmpty(roots []string) bool {
+	for _, root := range roots {
+		entries, err := os.ReadDir(root)
+		if err != nil && !os.IsNotExist(err) {
+			return false
+		}
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name(), ".") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ensureGlobalRoot handles a missing or empty global store on first run. On
+// an interactive terminal it offers to create the default category
+// directories. If that is declined or impossible, a missing store yields
+// guidance on creating it; an existing one is left as it is.
+func ensureGlobalRoot(root string) error {
+	guidance := fmt.Errorf("global store %s does not exist\n"+
+		"Create it with subdirectories such as %s, or set resources_dir in your config",
+		root, strings.Join(defaultCategories, ", "))
+	prompt := fmt.Sprintf("Global store %s does not exist.\nCreate it with %s? [y/N] ", root, strings.Join(defaultCategories, ", "))
+	if _, err := os.Stat(root); err == nil {
+		guidance = nil
+		prompt = fmt.Sprintf("Global store %s is empty.\nCreate %s in it? [y/N] ", root, strings.Join(defaultCategories, ", "))
+	}
+
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return guidance
+	}
+
+	fmt.Print(prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return guidance
+	}
+
+	for _, name := range defaultCategories {
+		if err := os.MkdirAll(filepath.Join(root, name), 0755); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Created %s\n", root)
+	return nil
+}
+
+// loadCategories scans the global stores for subdirectories. A category
+// present in several stores is listed once, with a directory per store.
+func (a *App) loadCategories() error {
+	labels := storeLabels(a.globalRoots, a.projectStore)
+
+	a.categories = nil
+	index := map[string]int{}  // category name → index in a.categories
+	var rootDirs []CategoryDir // stores with files at their top level
+	for s, root := range a.globalRoots {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			return err
+		}
+
+		hasFiles := false
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			if !entry.IsDir() {
+				hasFiles = hasFiles || !isDirEntry(root, entry)
+				continue
+			}
+			dir := CategoryDir{Path: filepath.Join(root, entry.Name()), Origin: labels[s]}
+			if i, ok := index[entry.Name()]; ok {
+				a.categories[i].GlobalDirs = append(a.categories[i].GlobalDirs, dir)
+				continue
+			}
+
+			subdir := a.subdirs[entry.Name()] // checked by applyConfig
+			index[entry.Name()] = len(a.categories)
+			a.categories = append(a.categories, Category{
+				Name:          entry.Name(),
+				GlobalDirs:    []CategoryDir{dir},
+				ProjectDir:    filepath.Join(a.claudeDir, entry.Name(), subdir),
+				Strategy:      a.categoryStrategy(entry.Name()),
+				ProjectSubdir: subdir,
+			})
+		}
+		if hasFiles {
+			rootDirs = append(rootDirs, CategoryDir{Path: root, Origin: labels[s]})
+		}
+	}
+	if _, taken := index[rootCategory]; len(rootDirs) > 0 && !taken {
+		a.categories = append(a.categories, Category{
+			Name:       rootCategory,
+			GlobalDirs: rootDirs,
+			ProjectDir: a.claudeDir,
+			Strategy:   a.categoryStrategy(rootCategory),
+			Root:       true,
+		})
+	}
+
+	// Categories named in category_order come first, in that order; the
+	// rest follow alphabetically, with the root files last.
+	rank := func(cat Category) int {
+		if i := slices.Index(a.categoryOrder, cat.Name); i >= 0 {
+			return i
+		}
+		if cat.Root {
+			return len(a.categoryOrder) + 1
+		}
+		return len(a.categoryOrder)
+	}
+	sort.Slice(a.categories, func(i, j int) bool {
+		ri, rj := rank(a.categories[i]), rank(a.categories[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return a.categories[i].Name < a.categories[j].Name
+	})
+
+	return nil
+}
+
+// categoryStrategy returns the apply strategy configured for the category
+// name, or StrategySymlink if there is none. applyConfig has already
+// rejected unknown strategies.
+func (a *App) categoryStrategy(name string) Strategy {
+	if s, ok := a.strategies[name]; ok {
+		return s
+	}
+	return StrategySymlink
+}
+
+// validStrategy reports whether s is one of the known apply strategies.
+func validStrategy(s Strategy) bool {
+	switch s {
+	case StrategySymlink, StrategyCopy, StrategyMerge, StrategyHardlink:
+		return true
+	}
+	return false
+}
+
+// isDirEntry reports whether entry in dir is a directory, following a
+// symlink to see what it points to.
+func isDirEntry(dir string, entry os.DirEntry) bool {
+	if entry.Type()&os.ModeSymlink == 0 {
+		return entry.IsDir()
+	}
+	info, err := os.Stat(filepath.Join(dir, entry.Name()))
+	return err == nil && info.IsDir()
+}
+
+// storeLabels returns the origin label shown for each store: its base name,
+// or its full path if the base name is ambiguous. With a single store there
+// is nothing to distinguish, so its label is empty. The project store is
+// labelled "project", and a lone personal store beside it "personal".
+func storeLabels(roots []string, projectStore string) []string {
+	labels := make([]string, len(roots))
+	if len(roots) < 2 {
+		return labels
+	}
+	seen := map[string]int{}
+	for _, root := range roots {
+		seen[filepath.Base(root)]++
+	}
+	for i, root := range roots {
+		labels[i] = filepath.Base(root)
+		switch {
+		case root == projectStore:
+			labels[i] = "project"
+		case projectStore != "" && len(roots) == 2:
+			labels[i] = "personal"
+		case seen[labels[i]] > 1:
+			labels[i] = root
+		}
+	}
+	return labels
+}
+
+// loadItems scans the active category and partitions into available and applied.
+func (a *App) loadItems() {
+	cat := a.categories[a.activeTabIdx]
+	a.pruneBrokenLinks(cat)
+	a.availableItems, a.appliedItems = scanCategory(cat, a.primaryDocs)
+	annotateGitState(filepath.Dir(a.claudeDir), cat, a.appliedItems)
+	a.sortAvailable(cat, a.availableItems)
+	a.sortItems(a.appliedItems)
+}
+
+// annotateGitState sets GitState on the applied items of cat when root is a
+// git repository: "gitignored" if git ignores the project entry, "tracked"
+// if it (or anything under it) is committed. Untracked entries stay blank.
+func annotateGitState(root string, cat Category, applied []Item) {
+	if len(applied) == 0 {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(root, ".git")); err != nil {
+		return
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return
+	}
+
+	rels := make([]string, len(applied))
+	for i, item := range applied {
+		rel, err := filepath.Rel(root, filepath.Join(cat.ProjectDir, item.linkName()))
+		if err != nil {
+			return
+		}
+		rels[i] = filepath.ToSlash(rel)
+	}
+
+	// check-ignore exits 1 when nothing is ignored; the output is all we need.
+	check := exec.Command("git", "-C", root, "check-ignore", "--stdin")
+	check.Stdin = strings.NewReader(strings.Join(rels, "\n") + "\n")
+	out, _ := check.Output()
+	ignored := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		ignored[line] = true
+	}
+
+	out, _ = exec.Command("git", append([]string{"-C", root, "ls-files", "-z", "--"}, rels...)...).Output()
+	tracked := strings.Split(string(out), "\x00")
+
+	for i, rel := range rels {
+		if ignored[rel] {
+			applied[i].GitState = "gitignored"
+			continue
+		}
+		for _, path := range tracked {
+			if path == rel || strings.HasPrefix(path, rel+"/") {
+				applied[i].GitState = "tracked"
+				break
+			}
+		}
+	}
+}
+
+// scanCategory lists the items in cat, partitioned into available and applied.
+// For symlink categories, project links are matched to global items by their
+// target, so items applied under a different name are still found. Items of
+// the same name from several stores are all listed, in store order.
+func scanCategory(cat Category, docs []string) (available, applied []Item) {
+	if linkedCategory(cat) {
+		// Entries seen through the link are the global items themselves.
+		return nil, nil
+	}
+
+	var items []Item
+	for _, dir := range cat.GlobalDirs {
+		entries, err := os.ReadDir(dir.Path)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			item := Item{
+				Name:        entry.Name(),
+				DisplayPath: entry.Name(), // categories are flat, so the path within one is the name
+				IsDir:       entry.IsDir(),
+				GlobalPath:  filepath.Join(dir.Path, entry.Name()),
+				Origin:      dir.Origin,
+			}
+			item.RealPath = canonicalPath(item.GlobalPath)
+			item.ModTime = itemModTime(item.GlobalPath, docs)
+			if entry.Type()&os.ModeSymlink != 0 {
+				// A linked entry is a file or directory by what it points to.
+				item.IsLink = true
+				if info, err := os.Stat(item.GlobalPath); err == nil {
+					item.IsDir = info.IsDir()
+				}
+			}
+			if cat.Root && item.IsDir {
+				continue // the store's category directories
+			}
+			items = append(items, item)
+		}
+	}
+
+	var links map[string]string
+	claimed := map[string]bool{} // project names linked to some item in cat
+	if cat.Strategy == StrategySymlink {
+		links = projectLinks(cat.ProjectDir)
+		for _, item := range items {
+			if name, ok := links[item.RealPath]; ok {
+				claimed[name] = true
+			}
+		}
+	}
+
+	seen := map[string]bool{} // names already listed by an earlier store
+	for _, item := range items {
+		first := !seen[item.Name]
+		seen[item.Name] = true
+		if cat.Strategy == StrategySymlink {
+			name, ok := links[item.RealPath]
+			if ok && isAppliedSymlink(filepath.Join(cat.ProjectDir, name), item.GlobalPath) {
+				if name != item.Name {
+					item.LinkName = name
+				}
+				applied = append(applied, item)
+				continue
+			}
+			if first && !claimed[item.Name] {
+				item.Warning = strayLinkWarning(filepath.Join(cat.ProjectDir, item.Name))
+			}
+		} else if first && isApplied(cat, item) {
+			// A copy cannot be traced to a store, so the first store wins.
+			applied = append(applied, item)
+			continue
+		}
+
+		if item.Warning != "" {
+			applied = append(applied, item)
+		} else {
+			available = append(available, item)
+		}
+	}
+
+	sort.SliceStable(available, func(i, j int) bool {
+		return available[i].Name < available[j].Name
+	})
+	sort.SliceStable(applied, func(i, j int) bool {
+		return applied[i].Name < applied[j].Name
+	})
+	return available, applied
+}
+
+// projectLinks enumerates the symlinks in dir and maps each one's canonical
+// target (see canonicalPath) to the symlink's name. Links are found whatever
+// they are called and however their target is spelled.
+func projectLinks(dir string) map[string]string {
+	links := map[string]string{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return links
+	}
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		linkPath := filepath.Join(dir, entry.Name())
+		if target, err := filepath.EvalSymlinks(linkPath); err == nil {
+			links[target] = entry.Name()
+		} else if target, err := linkTarget(linkPath); err == nil {
+			links[target] = entry.Name() // broken link: keep the lexical target
+		}
+	}
+	return links
+}
+
+// canonicalPath resolves every symlink in path, so a global item compares
+// equal to links that reach it through a symlinked store directory or a
+// relative path. It falls back to the lexical absolute path.
+func canonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return absPath(path)
+}
+
+// linkTarget returns the absolute path a symlink points to, resolving
+// relative targets against the link's directory.
+func linkTarget(linkPath string) (string, error) {
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(linkPath), target)
+	}
+	return filepath.Abs(target)
+}
+
+// absPath returns the absolute form of path, or path itself on error.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// loadAppliedCounts recomputes the number of applied items for every category.
+func (a *App) loadAppliedCounts() {
+	a.appliedCounts = appliedCounts(a.categories, a.activeTabIdx, len(a.appliedItems), a.primaryDocs)
+}
+
+// appliedCounts returns the applied item count for each category. The active
+// category's count is already known from its scan and is passed in directly.
+func appliedCounts(categories []Category, activeIdx, activeCount int, docs []string) []int {
+	counts := make([]int, len(categories))
+	for i, cat := range categories {
+		if i == activeIdx {
+			counts[i] = activeCount
+			continue
+		}
+		counts[i] = countApplied(cat, docs)
+	}
+	return counts
+}
+
+// countApplied returns how many items in cat are applied to the project.
+func countApplied(cat Category, docs []string) int {
+	_, applied := scanCategory(cat, docs)
+	count := 0
+	for _, item := range applied {
+		if item.Warning == "" {
+			count++
+		}
+	}
+	return count
+}
+
+// itemModTime returns when an item last changed. A directory's own time only
+// moves when entries are added or removed, so its primary doc counts too.
+func itemModTime(path string, docs []string) time.Time {
+	mod := modTime(path)
+	if doc := primaryDoc(path, docs); doc != "" {
+		if docMod := modTime(doc); docMod.After(mod) {
+			mod = docMod
+		}
+	}
+	return mod
+}
+
+// linkedCategory reports whether cat's project directory is itself a symlink
+// to the category's global directory, applying the whole category at once.
+func linkedCategory(cat Category) bool {
+	info, err := os.Lstat(cat.ProjectDir)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	resolved := canonicalPath(cat.ProjectDir)
+	for _, dir := range cat.GlobalDirs {
+		if canonicalPath(dir.Path) == resolved {
+			return true
+		}
+	}
+	return false
+}
+
+// isAppliedSymlink checks if projectPath is a symlink pointing to globalPath.
+func isAppliedSymlink(projectPath, globalPath string) bool {
+	info, err := os.Lstat(projectPath)
+	if err != nil {
+		return false
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	absTarget, err := linkTarget(projectPath)
+	if err != nil {
+		return false
+	}
+	absGlobal, err := filepath.Abs(globalPath)
+	if err != nil {
+		return false
+	}
+	if absTarget != absGlobal {
+		// The link may still reach the item via a different spelling, e.g.
+		// through a symlinked store directory.
+		resolved, err := filepath.EvalSymlinks(projectPath)
+		return err == nil && resolved == canonicalPath(globalPath)
+	}
+	// Validate the target still exists
+	if _, err := os.Stat(projectPath); err != nil {
+		return false // broken; see pruneBrokenLinks
+	}
+	return true
+}
+
+// pruneBrokenLinks removes the project links in cat that point at a store
+// entry which is itself a broken symlink; such items are listed as
+// available. Scans leave these links alone so they can run off the UI
+// goroutine; this runs on it, before the active category is scanned.
+func (a *App) pruneBrokenLinks(cat Category) {
+	if a.readOnly || cat.Strategy == StrategyCopy || cat.Strategy == StrategyHardlink || linkedCategory(cat) {
+		return
+	}
+	for target, name := range projectLinks(cat.ProjectDir) {
+		link := filepath.Join(cat.ProjectDir, name)
+		if _, err := os.Stat(link); err == nil {
+			continue
+		}
+		if _, err := os.Lstat(target); err != nil {
+			continue // not a store entry, or one that was deleted: a stray link
+		}
+		for _, dir := range cat.GlobalDirs {
+			if filepath.Dir(target) == absPath(dir.Path) {
+				os.Remove(link)
+				break
+			}
+		}
+	}
+}
+
+// strayLinkWarning reports when projectPath is a symlink that does not point
+// at the global item of the same name — typically a link that survived the
+// global store being moved. It returns "" when projectPath is not a symlink.
+func strayLinkWarning(projectPath string) string {
+	info, err := os.Lstat(projectPath)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return ""
+	}
+	target, err := os.Readlink(projectPath)
+	if err != nil {
+		return ""
+	}
+	if _, err := os.Stat(projectPath); err != nil {
+		return fmt.Sprintf("broken link to %s", target)
+	}
+	return fmt.Sprintf("links to %s instead of the global store", target)
+}
+
+func (a *App) setupUI() {
+	a.app = tview.NewApplication()
+	if a.noColor {
+		if screen, err := tcell.NewScreen(); err == nil {
+			a.app.SetScreen(monochromeScreen{screen})
+		}
+	}
+
+	// Tab bar
+	a.tabBar = tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+
+	// Available list
+	a.availableList = tview.NewList().
+		ShowSecondaryText(a.showDescs).
+		SetSecondaryTextColor(tcell.ColorGray).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(a.selectionColor).
+		SetSelectedTextColor(tcell.ColorWhite)
+	a.availableList.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorDefault)
+
+	a.availableList.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if a.isGroupHeader(a.availableList, index) {
+			// Mouse clicks and list-native keys can land on a header; move
+			// to the nearest item instead, preferring the one below.
+			if row, ok := a.stepCursor(a.availableList, index, 1, false); ok {
+				a.availableList.SetCurrentItem(row)
+			} else if row, ok := a.stepCursor(a.availableList, index, -1, false); ok {
+				a.availableList.SetCurrentItem(row)
+			}
+			return
+		}
+		if a.currentPanelIdx == 0 {
+			a.updatePreview()
+		}
+	})
+
+	// Applied list
+	a.appliedList = tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(a.selectionColor).
+		SetSelectedTextColor(tcell.ColorWhite)
+	a.appliedList.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorDefault)
+
+	a.appliedList.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if a.currentPanelIdx == 1 {
+			a.updatePreview()
+		}
+	})
+
+	// Preview
+	a.previewView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetRegions(true).
+		SetScrollable(true)
+	a.previewView.SetBorder(true).
+		SetTitle(" Preview ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorDefault)
+	a.previewView.SetBackgroundColor(a.previewBackground())
+
+	a.previewGlobal = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetScrollable(true)
+	a.previewGlobal.SetBorder(true).
+		SetTitle(" Global ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.ColorDefault)
+	a.previewGlobal.SetBackgroundColor(a.previewBackground())
+	a.previewFlex = tview.NewFlex().
+		AddItem(a.previewGlobal, 0, 0, false).
+		AddItem(a.previewView, 0, 1, false)
+
+	// Status bar
+	a.statusBar = tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+
+	// Info line, shown only while the Applied panel is focused
+	a.infoBar = tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+
+	// Navigable panels (preview is not navigable)
+	a.panels = []tview.Primitive{a.availableList, a.appliedList}
+
+	// Layout
+	a.leftFlex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.tabBar, 1, 0, false).
+		AddItem(a.availableList, 0, 1, true).
+		AddItem(a.appliedList, 0, 1, false)
+
+	a.mainFlex = tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(a.leftFlex, 0, 1, true).
+		AddItem(a.previewFlex, 0, 2, false)
+	a.setPreviewWidth(a.previewWidth)
+	a.setAppliedSize(a.appliedSize)
+
+	a.rootFlex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.mainFlex, 0, 1, true).
+		AddItem(a.infoBar, 0, 0, false).
+		AddItem(a.statusBar, 1, 0, false)
+
+	a.setupKeybindings()
+	a.app.SetFocus(a.panels[0])
+	a.updateBorderColors()
+
+	a.pages = tview.NewPages().
+		AddPage("main", a.rootFlex, true, true)
+	a.app.SetRoot(a.pages, true)
+
+	a.app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		width, height := screen.Size()
+		if width != a.screenWidth || height != a.screenHeight {
+			a.screenWidth, a.screenHeight = width, height
+			// Layout changes may move focus, which can't happen while the
+			// application is drawing.
+			go a.app.QueueUpdateDraw(func() { a.handleResize(screen) })
+		}
+		return false
+	})
+}
+
+// handleResize adapts the layout to a new terminal size: it switches between
+// the single- and two-column layouts, refits the modals sized to the screen,
+// and repaints every cell so no stale borders are left behind.
+func (a *App) handleResize(screen tcell.Screen) {
+	a.setCompact(a.screenWidth < compactWidth)
+	if a.zoomOpen {
+		resizeModal(a.zoomFrame, a.screenWidth-4, a.screenHeight-2)
+	}
+	if a.treeOpen {
+		width, height := a.treeModalSize()
+		resizeModal(a.treeFrame, width, height)
+	}
+	if a.outlineOpen {
+		width, height := a.treeModalSize()
+		resizeModal(a.outlineFrame, width, height)
+	}
+	screen.Sync()
+}
+
+// Preview column width bounds and step, in percent of the screen. The
+// default matches the original 1:2 split.
+const (
+	defaultPreviewWidth = 67
+	minPreviewWidth     = 20
+	maxPreviewWidth     = 80
+	previewWidthStep    = 5
+)
+
+// setPreviewWidth sizes the list and preview columns, clamping width to the
+// allowed range (0 selects the default).
+func (a *App) setPreviewWidth(width int) {
+	if width == 0 {
+		width = defaultPreviewWidth
+	}
+	a.previewWidth = min(max(width, minPreviewWidth), maxPreviewWidth)
+	a.mainFlex.ResizeItem(a.leftFlex, 0, 100-a.previewWidth)
+	a.mainFlex.ResizeItem(a.previewFlex, 0, a.previewWidth)
+}
+
+// resizePreview widens (delta > 0) or narrows the preview column and saves
+// the new width to the config file.
+func (a *App) resizePreview(delta int) {
+	if a.compact {
+		return
+	}
+	previous := a.previewWidth
+	a.setPreviewWidth(a.previewWidth + delta)
+	if a.previewWidth == previous {
+		return
+	}
+	if err := setConfigValue("preview_width", a.previewWidth); err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] saving preview width: %v", err))
+		return
+	}
+	a.statusBar.SetText(fmt.Sprintf(" Preview width [green]%d%%[-]", a.previewWidth))
+}
+
+// setAppliedSize resizes the Applied panel within the list column. Unknown
+// sizes fall back to even.
+func (a *App) setAppliedSize(size AppliedSize) {
+	switch size {
+	case AppliedSmall:
+		a.leftFlex.ResizeItem(a.availableList, 0, 3)
+		a.leftFlex.ResizeItem(a.appliedList, 0, 1)
+	case AppliedCollapsed:
+		a.leftFlex.ResizeItem(a.availableList, 0, 1)
+		a.leftFlex.ResizeItem(a.appliedList, 3, 0)
+	default:
+		size = AppliedEven
+		a.leftFlex.ResizeItem(a.availableList, 0, 1)
+		a.leftFlex.ResizeItem(a.appliedList, 0, 1)
+	}
+	a.appliedSize = size
+}
+
+// cycleAppliedSize steps the Applied panel through even, small and
+// collapsed, and saves the choice to the config file.
+func (a *App) cycleAppliedSize() {
+	next := map[AppliedSize]AppliedSize{
+		AppliedEven:      AppliedSmall,
+		AppliedSmall:     AppliedCollapsed,
+		AppliedCollapsed: AppliedEven,
+	}
+	a.setAppliedSize(next[a.appliedSize])
+	if err := setConfigValue("applied_size", string(a.appliedSize)); err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] saving applied size: %v", err))
+		return
+	}
+	a.statusBar.SetText(fmt.Sprintf(" Applied panel [green]%s[-]", a.appliedSize))
+}
+
+// monochromeScreen drops colors from everything drawn. Cells drawn with a
+// background color (selections, search matches, input fields) are shown in
+// reverse video instead so they stay visible.
+type monochromeScreen struct {
+	tcell.Screen
+}
+
+func (s monochromeScreen) SetContent(x, y int, primary rune, combining []rune, style tcell.Style) {
+	_, bg, attrs := style.Decompose()
+	if bg != tcell.ColorDefault {
+		attrs |= tcell.AttrReverse
+	}
+	s.Screen.SetContent(x, y, primary, combining, tcell.StyleDefault.Attributes(attrs))
+}
+
+// togglePreviewColumn hides or shows the preview column for the rest of the
+// session, giving the lists the full width while hidden.
+func (a *App) togglePreviewColumn() {
+	a.previewHidden = !a.previewHidden
+	if a.previewHidden {
+		if a.previewFocused {
+			a.focusPanel(a.currentPanelIdx)
+		}
+		a.mainFlex.RemoveItem(a.previewFlex)
+	} else {
+		a.mainFlex.AddItem(a.previewFlex, 0, a.previewWidth, false)
+	}
+	a.updatePreview()
+}
+
+// previewHiddenHint reports whether the preview column is hidden, telling
+// the user how to bring it back.
+func (a *App) previewHiddenHint() bool {
+	if a.previewHidden && !a.compact {
+		a.statusBar.SetText(" [yellow]Preview hidden — " + a.pressKey("showPreview") + " to show it[-]")
+		return true
+	}
+	return false
+}
+
+// compactWidth is the terminal width below which the preview column is hidden
+// and shown on demand as a full-screen page instead.
+const compactWidth = 80
+
+// setCompact switches between the two-column and single-column layouts.
+func (a *App) setCompact(compact bool) {
+	if compact == a.compact {
+		return
+	}
+	a.compact = compact
+
+	if compact {
+		if a.previewFocused {
+			a.focusPanel(a.currentPanelIdx)
+		}
+		a.mainFlex.RemoveItem(a.previewFlex)
+	} else {
+		if a.previewOpen {
+			a.closePreview()
+		}
+		if !a.previewHidden {
+			a.mainFlex.AddItem(a.previewFlex, 0, a.previewWidth, false)
+		}
+	}
+	a.updateStatusBar()
+}
+
+func (a *App) setupKeybindings() {
+	a.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Modal priority chain
+		if a.confirmOpen {
+			switch {
+			case event.Rune() == 'y' || event.Rune() == 'Y' || event.Key() == tcell.KeyEnter:
+				action := a.confirmAction
+				a.closeConfirm()
+				action()
+			case event.Rune() == 'n' || event.Rune() == 'N' || event.Rune() == 'q' || event.Key() == tcell.KeyEsc:
+				a.closeConfirm()
+			case event.Rune() == 'j' || event.Rune() == 'k' || event.Key() == tcell.KeyDown || event.Key() == tcell.KeyUp:
+				return event // scrolls a long confirmation, such as confirmApplyDir's
+			}
+			return nil
+		}
+		if a.previewOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == 'p':
+				a.closePreview()
+				return nil
+			case event.Rune() == 'J':
+				row, col := a.previewView.GetScrollOffset()
+				a.previewView.ScrollTo(row+1, col)
+				return nil
+			case event.Rune() == 'K':
+				row, col := a.previewView.GetScrollOffset()
+				if row > 0 {
+					a.previewView.ScrollTo(row-1, col)
+				}
+				return nil
+			}
+			return event
+		}
+		if a.promptOpen || a.pickerOpen || a.projectsOpen || a.paletteOpen || a.historyOpen || a.chainOpen || a.reviewOpen || a.welcomeOpen || a.pathsOpen {
+			return event
+		}
+		if a.zoomOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc && a.searchQuery != "":
+				a.clearSearch()
+				a.renderPreview()
+				a.updateStatusBar()
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == 'f':
+				a.closeZoom()
+			case event.Rune() == '/':
+				a.showSearch()
+			case event.Rune() == 'n':
+				a.nextMatch(1)
+			case event.Rune() == 'N':
+				a.nextMatch(-1)
+			case event.Rune() == '#':
+				a.zoomLineNumbers = !a.zoomLineNumbers
+				a.renderZoom()
+			case event.Rune() == 'J':
+				row, col := a.zoomView.GetScrollOffset()
+				a.zoomView.ScrollTo(row+1, col)
+			case event.Rune() == 'K':
+				row, col := a.zoomView.GetScrollOffset()
+				if row > 0 {
+					a.zoomView.ScrollTo(row-1, col)
+				}
+			default:
+				return event
+			}
+			return nil
+		}
+		if a.treeOpen {
+			switch {
+			case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+				a.closeTree()
+			case event.Rune() == '+' || event.Rune() == '=':
+				a.changeTreeDepth(1)
+			case event.Rune() == '-':
+				a.changeTreeDepth(-1)
+			case event.Rune() == 'J':
+				row, col := a.treePreview.GetScrollOffset()
+				a.treePreview.ScrollTo(row+1, col)
+			case event.Rune() == 'K':
+				row, col := a.treePreview.GetScrollOffset()
+				if row > 0 {
+					a.treePreview.ScrollTo(row-1, col)
+				}
+			default:
+				return event
+			}
+			return nil
+		}
+		if a.outlineOpen {
+			if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
+				a.closeOutline()
+				return nil
+			}
+			return event
+		}
+		if a.helpOpen {
+			if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
+				a.closeHelp()
+				return nil
+			}
+			return event
+		}
+		if a.jumpLabels != nil {
+			a.handleJumpKey(event)
+			return nil
+		}
+
+		if a.previewFocused {
+			switch event.Rune() {
+			case 'g', 'G':
+				return event // the preview jumps to its top or bottom itself
+			case 'd', 'u':
+				a.scrollPreviewHalfPage(event.Rune() == 'd')
+				return nil
+			}
+			switch a.keyActions[keyOf(event)] {
+			case "cursorDown", "cursorUp":
+				return event // the preview scrolls itself
+			case "toggleSelected", "moveToApplied", "moveToAvailable", "applyAs":
+				a.statusBar.SetText(" [yellow]Preview focused — " + a.pressKey("focusAvailable") + " to go back to a list to apply or remove[-]")
+				return nil
+			}
+		}
+		if a.handleCount(event) {
+			return nil
+		}
+		if action, ok := a.keymap[keyOf(event)]; ok {
+			action()
+			return nil
+		}
+		return event
+	})
+}
+
+// --- Keymap ---
+
+// keyID identifies a key press: a rune for printable keys, or a special key.
+type keyID struct {
+	key tcell.Key
+	r   rune
+}
+
+func keyOf(event *tcell.EventKey) keyID {
+	if event.Key() == tcell.KeyRune {
+		return keyID{tcell.KeyRune, event.Rune()}
+	}
+	return keyID{event.Key(), 0}
+}
+
+// parseKey parses a key name from keys.yaml: a single character ("j"),
+// "Space", or a tcell key name such as "Enter", "Tab", "Left", or "Ctrl-N".
+func parseKey(name string) (keyID, bool) {
+	if name == "Space" {
+		return keyID{tcell.KeyRune, ' '}, true
+	}
+	if runes := []rune(name); len(runes) == 1 {
+		return keyID{tcell.KeyRune, runes[0]}, true
+	}
+	for key, keyName := range tcell.KeyNames {
+		if strings.EqualFold(keyName, name) {
+			return keyID{key, 0}, true
+		}
+	}
+	return keyID{}, false
+}
+
+// defaultKeys maps action names to their default keys.
+var defaultKeys = map[string][]string{
+	"quit":               {"q"},
+	"escape":             {"Esc"},
+	"focusAvailable":     {"1"},
+	"focusApplied":       {"2"},
+	"focusPreview":       {"3"},
+	"prevPanel":          {"h", "Backtab"},
+	"nextPanel":          {"l", "Tab"},
+	"cursorDown":         {"j"},
+	"cursorUp":           {"k"},
+	"jumpToItem":         {"g"},
+	"scrollPreviewDown":  {"J"},
+	"scrollPreviewUp":    {"K"},
+	"prevTab":            {"["},
+	"nextTab":            {"]"},
+	"categoryPicker":     {"T"},
+	"toggleSelected":     {"Space", "Enter"},
+	"moveToApplied":      {"Right"},
+	"moveToAvailable":    {"Left"},
+	"applyAs":            {"m"},
+	"applyAll":           {"A"},
+	"removeAll":          {"X"},
+	"undo":               {"u"},
+	"history":            {"H"},
+	"copyPath":           {"c"},
+	"copyContent":        {"C"},
+	"linkChain":          {"i"},
+	"pathInfo":           {"I"},
+	"editItem":           {"e"},
+	"editCategory":       {"E"},
+	"pasteItem":          {"V"},
+	"duplicateItem":      {"D"},
+	"toggleFavorite":     {"*"},
+	"applyFavorites":     {"F"},
+	"reviewFavorites":    {"b"},
+	"groupAvailable":     {"G"},
+	"toggleDescriptions": {"d"},
+	"togglePaths":        {"w"},
+	"reload":             {"r", "F5"},
+	"reloadConfig":       {"R"},
+	"reverseSort":        {"S"},
+	"usageSort":          {"U"},
+	"applyToProjects":    {"P"},
+	"splitPreview":       {"v"},
+	"linkCategory":       {"L"},
+	"widenPreview":       {"<"},
+	"narrowPreview":      {">"},
+	"resizeApplied":      {"="},
+	"syncProjectConfig":  {"y"},
+	"writeProjectConfig": {"Y"},
+	"exportBundle":       {"B"},
+	"showTree":           {"t"},
+	"showPreview":        {"p"},
+	"zoomPreview":        {"f"},
+	"outlinePreview":     {"o"},
+	"jumpOverlay":        {"'"},
+	"search":             {"/"},
+	"nextMatch":          {"n"},
+	"prevMatch":          {"N"},
+	"help":               {"?"},
+	"commandPalette":     {":", "Ctrl-P"},
+}
+
+// actionTitles describes each action in the command palette. Actions without
+// a title are not listed there.
+var actionTitles = map[string]string{
+	"quit":               "Quit",
+	"focusAvailable":     "Focus the Available panel",
+	"focusApplied":       "Focus the Applied panel",
+	"focusPreview":       "Focus the preview",
+	"prevPanel":          "Previous panel",
+	"nextPanel":          "Next panel",
+	"scrollPreviewDown":  "Scroll preview down",
+	"scrollPreviewUp":    "Scroll preview up",
+	"prevTab":            "Previous category",
+	"nextTab":            "Next category",
+	"categoryPicker":     "Go to category",
+	"toggleSelected":     "Apply or remove the selected item",
+	"applyAs":            "Apply under a different name",
+	"applyAll":           "Apply all items",
+	"removeAll":          "Remove all items",
+	"undo":               "Undo last apply or remove",
+	"history":            "Items changed this session",
+	"copyPath":           "Copy item path to clipboard",
+	"copyContent":        "Copy item contents to clipboard",
+	"linkChain":          "Show the symlink chain of an applied item",
+	"pathInfo":           "Show the store and project paths",
+	"editItem":           "Open item in editor",
+	"editCategory":       "Open category directory in editor",
+	"pasteItem":          "New item from clipboard",
+	"duplicateItem":      "Duplicate item under a new name",
+	"toggleFavorite":     "Star or unstar item",
+	"applyFavorites":     "Apply starred items",
+	"reviewFavorites":    "Review starred items in one view",
+	"groupAvailable":     "Cycle Available grouping",
+	"toggleDescriptions": "Show or hide item descriptions",
+	"togglePaths":        "Show item paths or names",
+	"reload":             "Rescan categories and items",
+	"reloadConfig":       "Reload config.yaml and keys.yaml",
+	"reverseSort":        "Reverse sort order",
+	"usageSort":          "Sort Available by use across projects",
+	"applyToProjects":    "Apply item to sibling projects",
+	"splitPreview":       "Split preview: global vs project",
+	"linkCategory":       "Link or unlink the whole category",
+	"widenPreview":       "Widen the preview",
+	"narrowPreview":      "Narrow the preview",
+	"resizeApplied":      "Resize the Applied panel",
+	"syncProjectConfig":  "Apply items listed in lazyclaude.yaml",
+	"writeProjectConfig": "Save applied items to lazyclaude.yaml",
+	"exportBundle":       "Export applied items to a bundle",
+	"showTree":           "Browse folder tree",
+	"showPreview":        "Hide or show the preview",
+	"zoomPreview":        "Full-screen preview",
+	"outlinePreview":     "Outline of a JSON or YAML file",
+	"jumpOverlay":        "Jump to a visible item by number",
+	"search":             "Search the preview",
+	"nextMatch":          "Next search match",
+	"prevMatch":          "Previous search match",
+	"help":               "Help",
+}
+
+// actions maps action names to their handlers.
+func (a *App) actions() map[string]func() {
+	return map[string]func(){
+		"quit": a.quit,
+		"escape": func() {
+			if a.searchQuery != "" {
+				a.clearSearch()
+				a.renderPreview()
+				a.updateStatusBar()
+				return
+			}
+			a.quit()
+		},
+		"focusAvailable": func() { a.focusPanel(0) },
+		"focusApplied":   func() { a.focusPanel(1) },
+		"focusPreview":   a.focusPreviewPanel,
+		"prevPanel":      a.prevPanel,
+		"nextPanel":      a.nextPanel,
+		"cursorDown":     a.cursorDown,
+		"cursorUp":       a.cursorUp,
+		"jumpToItem":     func() { a.jumpToItem(1) }, // a count picks another item
+		"scrollPreviewDown": func() {
+			if a.previewHiddenHint() {
+				return
+			}
+			row, col := a.previewView.GetScrollOffset()
+			a.previewView.ScrollTo(row+1, col)
+		},
+		"scrollPreviewUp": func() {
+			if a.previewHiddenHint() {
+				return
+			}
+			row, col := a.previewView.GetScrollOffset()
+			if row > 0 {
+				a.previewView.ScrollTo(row-1, col)
+			}
+		},
+		"prevTab":        a.prevTab,
+		"nextTab":        a.nextTab,
+		"categoryPicker": a.showCategoryPicker,
+		"toggleSelected": a.toggleSelected,
+		"moveToApplied": func() {
+			if a.currentPanelIdx == 0 {
+				a.applySelected()
+			}
+		},
+		"moveToAvailable": func() {
+			if a.currentPanelIdx == 1 {
+				a.removeSelected()
+			}
+		},
+		"applyAs":            a.applySelectedAs,
+		"applyAll":           a.confirmApplyAll,
+		"removeAll":          a.confirmRemoveAll,
+		"undo":               a.undo,
+		"history":            a.showHistory,
+		"copyPath":           a.copySelectedPath,
+		"copyContent":        a.copySelectedContent,
+		"linkChain":          a.showLinkChain,
+		"pathInfo":           a.showPathInfo,
+		"pasteItem":          a.pasteAsItem,
+		"duplicateItem":      a.duplicateItem,
+		"editItem":           a.editSelected,
+		"editCategory":       a.editCategory,
+		"toggleFavorite":     a.toggleFavorite,
+		"applyFavorites":     a.applyFavorites,
+		"reviewFavorites":    a.reviewFavorites,
+		"groupAvailable":     a.cycleGroupMode,
+		"toggleDescriptions": a.toggleDescriptions,
+		"togglePaths":        a.togglePaths,
+		"reload":             a.reload,
+		"reloadConfig":       a.reloadConfig,
+		"reverseSort":        a.toggleSortDirection,
+		"usageSort":          a.toggleUsageSort,
+		"applyToProjects":    a.showProjectPicker,
+		"splitPreview":       a.toggleSplitPreview,
+		"linkCategory":       a.toggleCategoryLink,
+		"widenPreview":       func() { a.resizePreview(previewWidthStep) },
+		"narrowPreview":      func() { a.resizePreview(-previewWidthStep) },
+		"resizeApplied":      a.cycleAppliedSize,
+		"syncProjectConfig":  a.syncProjectConfig,
+		"writeProjectConfig": a.confirmWriteProjectConfig,
+		"exportBundle":       a.exportBundle,
+		"showTree":           a.showTree,
+		"showPreview": func() {
+			if a.compact {
+				a.showPreview()
+			} else {
+				a.togglePreviewColumn()
+			}
+		},
+		"zoomPreview":    a.showZoom,
+		"outlinePreview": a.showOutline,
+		"jumpOverlay":    a.showJumpOverlay,
+		"search":         a.showSearch,
+		"nextMatch":      func() { a.nextMatch(1) },
+		"prevMatch":      func() { a.nextMatch(-1) },
+		"help":           a.showHelp,
+		"commandPalette": a.showCommandPalette,
+	}
+}
+
+// KeyList is one key or a list of keys in keys.yaml.
+type KeyList []string
+
+// UnmarshalYAML accepts either a single key or a sequence of keys.
+func (k *KeyList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*k = KeyList{node.Value}
+		return nil
+	}
+	var keys []string
+	if err := node.Decode(&keys); err != nil {
+		return err
+	}
+	*k = keys
+	return nil
+}
+
+// loadKeyOverrides reads keys.yaml from the config directory. A missing file
+// yields no overrides.
+func loadKeyOverrides() (map[string]KeyList, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "keys.yaml"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var overrides map[string]KeyList
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("parsing keys.yaml: %w", err)
+	}
+	return overrides, nil
+}
+
+// buildKeymap resolves the default keys plus user overrides into a lookup
+// table. An overridden action loses its default keys, and user keys take
+// precedence over any default bound to the same key. Unknown actions and
+// keys are skipped and reported as warnings.
+func (a *App) buildKeymap(overrides map[string]KeyList) []string {
+	actions := a.actions()
+	var warnings []string
+
+	bindings := map[string][]string{}
+	for action, keys := range defaultKeys {
+		if _, overridden := overrides[action]; !overridden {
+			bindings[action] = keys
+		}
+	}
+
+	a.keymap = map[keyID]func(){}
+	a.keyActions = map[keyID]string{}
+	bind := func(action string, keys []string) {
+		for _, name := range keys {
+			key, ok := parseKey(name)
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("unknown key %q for %s", name, action))
+				continue
+			}
+			a.keymap[key] = actions[action]
+			a.keyActions[key] = action
+		}
+	}
+	for action, keys := range bindings {
+		bind(action, keys)
+	}
+
+	names := make([]string, 0, len(overrides))
+	for action := range overrides {
+		names = append(names, action)
+	}
+	sort.Strings(names)
+	for _, action := range names {
+		if _, ok := actions[action]; !ok {
+			warnings = append(warnings, fmt.Sprintf("unknown action %q", action))
+			continue
+		}
+		bind(action, overrides[action])
+	}
+	return warnings
+}
+
+// --- Quit ---
+
+// pendingChange is an in-session change that would be lost on quit. The id
+// tells apart changes that share a description.
+type pendingChange struct {
+	id   int
+	desc string
+}
+
+// beginPending registers an in-session change that would be lost on quit and
+// returns a function that clears it.
+func (a *App) beginPending(desc string) (done func()) {
+	a.pendingSeq++
+	id := a.pendingSeq
+	a.pending = append(a.pending, pendingChange{id: id, desc: desc})
+	return func() {
+		for i, p := range a.pending {
+			if p.id == id {
+				a.pending = append(a.pending[:i], a.pending[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// quit stops the application, first asking for confirmation when
+// confirm_quit is set and changes are pending.
+func (a *App) quit() {
+	if !a.confirmQuit || len(a.pending) == 0 {
+		a.app.Stop()
+		return
+	}
+	descs := make([]string, len(a.pending))
+	for i, p := range a.pending {
+		descs[i] = p.desc
+	}
+	a.showConfirm(" Quit ",
+		fmt.Sprintf("Pending: %s.\nQuit anyway?", strings.Join(descs, ", ")),
+		a.app.Stop)
+}
+
+// --- Tab switching ---
+
+func (a *App) nextTab() {
+	a.activeTabIdx = (a.activeTabIdx + 1) % len(a.categories)
+	a.refreshAsync()
+}
+
+func (a *App) prevTab() {
+	a.activeTabIdx = (a.activeTabIdx - 1 + len(a.categories)) % len(a.categories)
+	a.refreshAsync()
+}
+
+// --- Category picker ---
+
+// showCategoryPicker opens a filterable list of categories; Enter jumps to
+// the selected one.
+func (a *App) showCategoryPicker() {
+	a.pickerOpen = true
+
+	input := tview.NewInputField().
+		SetLabel("> ").
+		SetFieldBackgroundColor(tcell.ColorDefault)
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(a.selectionColor).
+		SetSelectedTextColor(tcell.ColorWhite)
+
+	var matches []int // category indices shown in the list
+	fill := func(filter string) {
+		list.Clear()
+		matches = nil
+		filter = strings.ToLower(filter)
+		for i, cat := range a.categories {
+			if !strings.Contains(strings.ToLower(cat.Name), filter) && !strings.Contains(strings.ToLower(a.theme.label(cat.Name)), filter) {
+				continue
+			}
+			name := tview.Escape(a.theme.label(cat.Name))
+			if i == a.activeTabIdx {
+				name = "[green::b]" + name + "[-:-:-]"
+				list.AddItem(name, "", 0, nil)
+				list.SetCurrentItem(len(matches))
+			} else {
+				list.AddItem(name, "", 0, nil)
+			}
+			matches = append(matches, i)
+		}
+	}
+	fill("")
+
+	input.SetChangedFunc(fill)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyDown, tcell.KeyCtrlN:
+			list.SetCurrentItem((list.GetCurrentItem() + 1) % max(list.GetItemCount(), 1))
+			return nil
+		case tcell.KeyUp, tcell.KeyCtrlP:
+			if idx := list.GetCurrentItem(); idx > 0 {
+				list.SetCurrentItem(idx - 1)
+			}
+			return nil
+		case tcell.KeyEnter:
+			idx := list.GetCurrentItem()
+			a.closeCategoryPicker()
+			if idx >= 0 && idx < len(matches) && matches[idx] != a.activeTabIdx {
+				a.activeTabIdx = matches[idx]
+				a.refreshAsync()
+			}
+			return nil
+		case tcell.KeyEsc:
+			a.closeCategoryPicker()
+			return nil
+		}
+		return event
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+	layout.SetBorder(true).
+		SetTitle(" Go to Category ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	a.pages.AddPage("picker", modal(layout, 40, min(len(a.categories)+3, 20)), true, true)
+	a.app.SetFocus(input)
+}
+
+func (a *App) closeCategoryPicker() {
+	a.pickerOpen = false
+	a.pages.RemovePage("picker")
+	a.restoreFocus()
+	a.updateBorderColors()
+}
+
+// --- Command palette ---
+
+// keyName is the keys.yaml spelling of key.
+func keyName(key keyID) string {
+	switch {
+	case key.key == tcell.KeyRune && key.r == ' ':
+		return "Space"
+	case key.key == tcell.KeyRune:
+		return string(key.r)
+	}
+	return tcell.KeyNames[key.key]
+}
+
+// boundKeys returns the keys bound to each action, sorted.
+func (a *App) boundKeys() map[string][]string {
+	keys := map[string][]string{}
+	for key, action := range a.keyActions {
+		keys[action] = append(keys[action], keyName(key))
+	}
+	for _, names := range keys {
+		sort.Strings(names)
+	}
+	return keys
+}
+
+// showCommandPalette opens a searchable list of every action with its keys;
+// Enter runs the highlighted one.
+func (a *App) showCommandPalette() {
+	a.paletteOpen = true
+	actions := a.actions()
+	keys := a.boundKeys()
+
+	names := make([]string, 0, len(actionTitles))
+	for name := range actionTitles {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return actionTitles[names[i]] < actionTitles[names[j]] })
+
+	input := tview.NewInputField().
+		SetLabel(": ").
+		SetFieldBackgroundColor(tcell.ColorDefault)
+	list := tview.NewList().
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(a.selectionColor).
+		SetSelectedTextColor(tcell.ColorWhite).
+		ShowSecondaryText(false)
+
+	var matches []string // action names shown in the list
+	fill := func(filter string) {
+		list.Clear()
+		matches = nil
+		filter = strings.ToLower(filter)
+		for _, name := range names {
+			title := actionTitles[name]
+			if !strings.Contains(strings.ToLower(title), filter) && !strings.Contains(strings.ToLower(name), filter) {
+				continue
+			}
+			text := title
+			if bound := keys[name]; len(bound) > 0 {
+				text += "  [darkgray]" + tview.Escape(strings.Join(bound, ", ")) + "[-]"
+			}
+			list.AddItem(text, "", 0, nil)
+			matches = append(matches, name)
+		}
+	}
+	fill("")
+
+	input.SetChangedFunc(fill)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyDown, tcell.KeyCtrlN:
+			list.SetCurrentItem((list.GetCurrentItem() + 1) % max(list.GetItemCount(), 1))
+			return nil
+		case tcell.KeyUp, tcell.KeyCtrlP:
+			if idx := list.GetCurrentItem(); idx > 0 {
+				list.SetCurrentItem(idx - 1)
+			}
+			return nil
+		case tcell.KeyEnter:
+			idx := list.GetCurrentItem()
+			a.closeCommandPalette()
+			if idx >= 0 && idx < len(matches) {
+				actions[matches[idx]]()
+			}
+			return nil
+		case tcell.KeyEsc:
+			a.closeCommandPalette()
+			return nil
+		}
+		return event
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+	layout.SetBorder(true).
+		SetTitle(" Commands ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	a.pages.AddPage("palette", modal(layout, 60, min(len(names)+3, 22)), true, true)
+	a.app.SetFocus(input)
+}
+
+func (a *App) closeCommandPalette() {
+	a.paletteOpen = false
+	a.pages.RemovePage("palette")
+	a.restoreFocus()
+	a.updateBorderColors()
+}
+
+// --- Sibling projects ---
+
+// defaultProjectMarkers are the entries that mark a directory as a project.
+// project_marker adds to them.
+var defaultProjectMarkers = []string{".claude", ".git"}
+
+// siblingProjects lists the directories next to the current project that
+// look like projects (they contain one of a.projectMarkers), by name.
+func (a *App) siblingProjects() []string {
+	projectRoot := filepath.Dir(a.claudeDir)
+	parent := filepath.Dir(projectRoot)
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return nil
+	}
+
+	var projects []string
+	for _, entry := range entries {
+		dir := filepath.Join(parent, entry.Name())
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || dir == projectRoot {
+			continue
+		}
+		for _, marker := range a.projectMarkers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				projects = append(projects, dir)
+				break
+			}
+		}
+	}
+	return projects
+}
+
+// showProjectPicker opens a multi-select list of sibling projects to apply
+// the selected item to.
+func (a *App) showProjectPicker() {
+	item := a.selectedItem()
+	if item == nil || a.blockedByReadOnly() {
+		return
+	}
+	projects := a.siblingProjects()
+	if len(projects) == 0 {
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]No sibling projects found next to %s[-]", tview.Escape(filepath.Dir(a.claudeDir))))
+		return
+	}
+	a.projectsOpen = true
+	cat := a.categories[a.activeTabIdx]
+	target := *item
+
+	checked := make([]bool, len(projects))
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(a.selectionColor).
+		SetSelectedTextColor(tcell.ColorWhite)
+	label := func(i int) string {
+		box := "[ ]"
+		if checked[i] {
+			box = "[green][x][-]"
+		}
+		return tview.Escape(box) + " " + tview.Escape(filepath.Base(projects[i]))
+	}
+	for i := range projects {
+		list.AddItem(label(i), "", 0, nil)
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		idx := list.GetCurrentItem()
+		switch {
+		case event.Rune() == ' ':
+			checked[idx] = !checked[idx]
+			list.SetItemText(idx, label(idx), "")
+		case event.Rune() == 'j':
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case event.Rune() == 'k':
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case event.Key() == tcell.KeyEnter:
+			var chosen []string
+			for i, project := range projects {
+				if checked[i] {
+					chosen = append(chosen, project)
+				}
+			}
+			if len(chosen) == 0 {
+				chosen = []string{projects[idx]}
+			}
+			a.closeProjectPicker()
+			a.applyToProjects(cat, target, chosen)
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+			a.closeProjectPicker()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[darkgray]Space mark, Enter apply, Esc cancel[-]")
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, true).
+		AddItem(hint, 1, 0, false)
+	layout.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Apply %s to Projects ", item.DisplayName())).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	a.pages.AddPage("projects", modal(layout, 50, min(len(projects)+3, 20)), true, true)
+	a.app.SetFocus(list)
+}
+
+func (a *App) closeProjectPicker() {
+	a.projectsOpen = false
+	a.pages.RemovePage("projects")
+	a.restoreFocus()
+	a.updateBorderColors()
+}
+
+// applyToProjects applies item to the same category of each project root and
+// reports how many succeeded and why any failed.
+func (a *App) applyToProjects(cat Category, item Item, projects []string) {
+	rel, err := filepath.Rel(filepath.Dir(a.claudeDir), a.claudeDir)
+	if err != nil {
+		rel = ".claude"
+	}
+
+	var failures []string
+	for _, project := range projects {
+		other := cat
+		other.ProjectDir = filepath.Join(project, rel, cat.Name, cat.ProjectSubdir)
+		if cat.Root {
+			other.ProjectDir = filepath.Join(project, rel)
+		}
+		merging := cat.Strategy == StrategyMerge && item.IsDir
+		var err error
+		if _, statErr := os.Lstat(filepath.Join(other.ProjectDir, item.linkName())); statErr == nil && !merging {
+			err = errors.New("already exists")
+		} else {
+			err = a.linkItem(other, item)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", filepath.Base(project), describeFSError(err)))
+			continue
+		}
+		a.noteUsage(other, item)
+	}
+
+	msg := fmt.Sprintf(" Applied %s to %d of %d projects", item.DisplayName(), len(projects)-len(failures), len(projects))
+	if len(failures) > 0 {
+		msg += fmt.Sprintf(" [red](%s)[-]", tview.Escape(strings.Join(failures, "; ")))
+	}
+	a.statusBar.SetText(msg)
+}
+
+// --- Panel navigation ---
+
+func (a *App) focusPanel(idx int) {
+	if idx >= 0 && idx < len(a.panels) {
+		a.currentPanelIdx = idx
+		a.previewFocused = false
+		a.app.SetFocus(a.panels[idx])
+		a.updateBorderColors()
+		a.updatePreview()
+		a.updateStatusBar()
+	}
+}
+
+// With focus_preview, the preview column comes after the Applied panel in
+// the Tab order.
+func (a *App) nextPanel() {
+	switch {
+	case a.previewFocused:
+		a.focusPanel(0)
+	case a.currentPanelIdx == len(a.panels)-1 && a.previewFocus && a.canFocusPreview():
+		a.focusPreview()
+	default:
+		a.focusPanel((a.currentPanelIdx + 1) % len(a.panels))
+	}
+}
+
+func (a *App) prevPanel() {
+	switch {
+	case a.previewFocused:
+		a.focusPanel(len(a.panels) - 1)
+	case a.currentPanelIdx == 0 && a.previewFocus && a.canFocusPreview():
+		a.focusPreview()
+	default:
+		a.focusPanel((a.currentPanelIdx - 1 + len(a.panels)) % len(a.panels))
+	}
+}
+
+// canFocusPreview reports whether the preview column can take focus: when
+// it is shown beside the lists.
+func (a *App) canFocusPreview() bool {
+	return !a.compact && !a.previewHidden
+}
+
+// focusPreviewPanel is the preview's numbered-panel key: it focuses the
+// preview column, or in the narrow layout opens the full-screen preview.
+func (a *App) focusPreviewPanel() {
+	switch {
+	case a.compact:
+		if a.selectedItem() != nil {
+			a.showPreview()
+		}
+	case a.previewHiddenHint():
+	default:
+		a.focusPreview()
+	}
+}
+
+// scrollPreviewHalfPage scrolls the focused preview by half its height.
+func (a *App) scrollPreviewHalfPage(down bool) {
+	_, _, _, height := a.previewView.GetInnerRect()
+	step := max(height/2, 1)
+	row, col := a.previewView.GetScrollOffset()
+	if !down {
+		step = -min(step, row)
+	}
+	a.previewView.ScrollTo(row+step, col)
+}
+
+// focusPreview moves focus to the preview column, keeping the list cursor
+// (and so the previewed item) where it is.
+func (a *App) focusPreview() {
+	a.previewFocused = true
+	a.app.SetFocus(a.previewView)
+	a.updateBorderColors()
+	a.updateStatusBar()
+}
+
+// restoreFocus gives focus back to the focused panel after a modal closes.
+func (a *App) restoreFocus() {
+	if a.previewFocused && a.canFocusPreview() {
+		a.app.SetFocus(a.previewView)
+		return
+	}
+	a.previewFocused = false
+	a.app.SetFocus(a.panels[a.currentPanelIdx])
+}
+
+func (a *App) updateBorderColors() {
+	for _, p := range a.panels {
+		if box, ok := p.(interface {
+			SetBorderColor(tcell.Color) *tview.Box
+		}); ok {
+			box.SetBorderColor(tcell.ColorDefault)
+		}
+		if list, ok := p.(*tview.List); ok {
+			list.SetSelectedBackgroundColor(tcell.ColorDefault)
+		}
+	}
+
+	a.previewView.SetBorderColor(tcell.ColorDefault)
+	if a.previewFocused {
+		a.previewView.SetBorderColor(tcell.ColorGreen)
+		return
+	}
+
+	focused := a.panels[a.currentPanelIdx]
+	if box, ok := focused.(interface {
+		SetBorderColor(tcell.Color) *tview.Box
+	}); ok {
+		box.SetBorderColor(tcell.ColorGreen)
+	}
+	if list, ok := focused.(*tview.List); ok {
+		list.SetSelectedBackgroundColor(a.selectionColor)
+	}
+}
+
+// --- Cursor movement ---
+
+func (a *App) cursorDown() {
+	if list, ok := a.panels[a.currentPanelIdx].(*tview.List); ok {
+		if row, ok := a.stepCursor(list, list.GetCurrentItem(), 1, a.wrapCursor); ok {
+			list.SetCurrentItem(row)
+		}
+		a.updatePreview()
+	}
+}
+
+func (a *App) cursorUp() {
+	if list, ok := a.panels[a.currentPanelIdx].(*tview.List); ok {
+		if row, ok := a.stepCursor(list, list.GetCurrentItem(), -1, a.wrapCursor); ok {
+			list.SetCurrentItem(row)
+		}
+		a.updatePreview()
+	}
+}
+
+// stepCursor returns the next selectable row after current in direction dir
+// (1 or -1), skipping group headers. ok is false if there is none.
+func (a *App) stepCursor(list *tview.List, current, dir int, wrap bool) (row int, ok bool) {
+	count := list.GetItemCount()
+	row = current
+	for range count {
+		row += dir
+		if row < 0 || row >= count {
+			if !wrap {
+				return current, false
+			}
+			row = (row + count) % count
+		}
+		if !a.isGroupHeader(list, row) {
+			return row, true
+		}
+	}
+	return current, false
+}
+
+// moveCursor moves the cursor n items down (or up for negative n), stopping
+// at the ends of the list.
+func (a *App) moveCursor(n int) {
+	list, ok := a.panels[a.currentPanelIdx].(*tview.List)
+	if !ok {
+		return
+	}
+	dir := 1
+	if n < 0 {
+		dir, n = -1, -n
+	}
+	row := list.GetCurrentItem()
+	for range n {
+		next, ok := a.stepCursor(list, row, dir, false)
+		if !ok {
+			break
+		}
+		row = next
+	}
+	list.SetCurrentItem(row)
+	a.updatePreview()
+}
+
+// jumpToItem moves the cursor to the n-th item (1-based) of the focused
+// list, not counting group headers. Counts past the end select the last item.
+func (a *App) jumpToItem(n int) {
+	list, ok := a.panels[a.currentPanelIdx].(*tview.List)
+	if !ok {
+		return
+	}
+	last := -1
+	for row := 0; row < list.GetItemCount(); row++ {
+		if a.isGroupHeader(list, row) {
+			continue
+		}
+		last = row
+		if n--; n == 0 {
+			break
+		}
+	}
+	if last >= 0 {
+		list.SetCurrentItem(last)
+		a.updatePreview()
+	}
+}
+
+// showJumpOverlay numbers the visible items of the focused list, like
+// vim-easymotion; typing a number moves the cursor to that item.
+func (a *App) showJumpOverlay() {
+	list, ok := a.panels[a.currentPanelIdx].(*tview.List)
+	if !ok || list.GetItemCount() == 0 {
+		return
+	}
+	offset, _ := list.GetOffset()
+	_, _, _, height := list.GetInnerRect()
+	if list == a.availableList && a.showDescs {
+		height /= 2 // items take two lines with descriptions
+	}
+
+	a.jumpLabels = map[int]int{}
+	a.jumpTexts = map[int]string{}
+	a.jumpTyped = ""
+	for row := offset; row < min(offset+height, list.GetItemCount()); row++ {
+		if a.isGroupHeader(list, row) {
+			continue
+		}
+		label := len(a.jumpLabels) + 1
+		main, secondary := list.GetItemText(row)
+		a.jumpLabels[label] = row
+		a.jumpTexts[row] = main
+		list.SetItemText(row, jumpLabel(label, main), secondary)
+	}
+	a.statusBar.SetText(" Jump: type an item number (Enter to confirm, Esc to cancel)")
+}
+
+func jumpLabel(label int, text string) string {
+	return fmt.Sprintf("[black:yellow]%d[-:-] %s", label, text)
+}
+
+// handleJumpKey takes a digit, Enter or Backspace while the jump overlay is
+// shown; any other key cancels it. The cursor moves as soon as the typed
+// number can't grow into another label.
+func (a *App) handleJumpKey(event *tcell.EventKey) {
+	switch {
+	case event.Rune() >= '0' && event.Rune() <= '9':
+		a.jumpTyped += string(event.Rune())
+		n, _ := strconv.Atoi(a.jumpTyped)
+		if _, ok := a.jumpLabels[n]; !ok && n*10 > len(a.jumpLabels) {
+			a.closeJumpOverlay(false)
+			return
+		}
+		if n*10 > len(a.jumpLabels) {
+			a.closeJumpOverlay(true)
+			return
+		}
+		a.statusBar.SetText(" Jump: " + a.jumpTyped)
+	case event.Key() == tcell.KeyEnter:
+		a.closeJumpOverlay(true)
+	case event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2:
+		if a.jumpTyped != "" {
+			a.jumpTyped = a.jumpTyped[:len(a.jumpTyped)-1]
+			a.statusBar.SetText(" Jump: " + a.jumpTyped)
+		}
+	default:
+		a.closeJumpOverlay(false)
+	}
+}
+
+// closeJumpOverlay restores the labeled rows and, if jump is set, moves the
+// cursor to the item whose label was typed.
+func (a *App) closeJumpOverlay(jump bool) {
+	list := a.panels[a.currentPanelIdx].(*tview.List)
+	for label, row := range a.jumpLabels {
+		if row >= list.GetItemCount() {
+			continue
+		}
+		// Rows rebuilt by a refresh meanwhile are left as they are.
+		if main, secondary := list.GetItemText(row); main == jumpLabel(label, a.jumpTexts[row]) {
+			list.SetItemText(row, a.jumpTexts[row], secondary)
+		}
+	}
+	n, _ := strconv.Atoi(a.jumpTyped)
+	row, ok := a.jumpLabels[n]
+	a.jumpLabels = nil
+	a.updateStatusBar()
+	if jump && ok {
+		list.SetCurrentItem(row)
+		a.updatePreview()
+	}
+}
+
+// countTimeout is how long a lone digit that is also bound to an action (the
+// panel jumps) waits for a motion before running that action.
+const countTimeout = 400 * time.Millisecond
+
+// handleCount accumulates a vim-style numeric prefix and applies it to the
+// next key: j/k move that many items and G jumps to that item. Other keys
+// drop the prefix. It reports whether the event was consumed.
+func (a *App) handleCount(event *tcell.EventKey) bool {
+	key := keyOf(event)
+	if r := event.Rune(); event.Key() == tcell.KeyRune && r >= '0' && r <= '9' && (a.count > 0 || r != '0') {
+		a.countAction = nil
+		if a.count == 0 {
+			a.countAction = a.keymap[key]
+		}
+		a.count = min(a.count*10+int(r-'0'), 99999)
+		a.countGen++
+		if a.countAction != nil {
+			gen := a.countGen
+			time.AfterFunc(countTimeout, func() {
+				a.app.QueueUpdateDraw(func() {
+					if gen == a.countGen && a.countAction != nil {
+						action := a.countAction
+						a.resetCount()
+						action()
+					}
+				})
+			})
+		}
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]%d[-]", a.count))
+		return true
+	}
+	if a.count == 0 {
+		return false
+	}
+
+	count, pending := a.count, a.countAction
+	a.resetCount()
+	switch a.keyActions[key] {
+	case "cursorDown":
+		a.moveCursor(count)
+		return true
+	case "cursorUp":
+		a.moveCursor(-count)
+		return true
+	case "jumpToItem":
+		a.jumpToItem(count)
+		return true
+	case "escape":
+		return true
+	}
+	if pending != nil {
+		pending()
+	}
+	return false
+}
+
+// resetCount drops a pending numeric prefix.
+func (a *App) resetCount() {
+	a.count = 0
+	a.countAction = nil
+	a.countGen++
+	a.updateStatusBar()
+}
+
+// --- Toggle (apply/remove) ---
+
+// blockedByReadOnly reports whether a mutating action must be skipped, and
+// tells the user why.
+func (a *App) blockedByReadOnly() bool {
+	if a.readOnly {
+		a.statusBar.SetText(" [yellow]read-only mode[-]")
+	}
+	return a.readOnly
+}
+
+func (a *App) toggleSelected() {
+	switch a.currentPanelIdx {
+	case 0: // Available panel → apply
+		a.applySelected()
+	case 1: // Applied panel → remove
+		a.removeSelected()
+	}
+}
+
+func (a *App) applySelected() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	idx := a.availableIndex()
+	if idx < 0 {
+		return
+	}
+
+	cat := a.categories[a.activeTabIdx]
+	item := a.availableItems[idx]
+
+	if appliesOntoItself(cat, item) {
+		a.statusBar.SetText(" [red]Error:[-] " + errSelfApply.Error())
+		return
+	}
+
+	target := filepath.Join(cat.ProjectDir, item.Name)
+	merging := cat.Strategy == StrategyMerge && item.IsDir
+	if _, err := os.Lstat(target); err == nil && !merging {
+		if isAppliedSymlink(target, item.GlobalPath) {
+			a.refreshAll()
+			return
+		}
+		a.showConfirm(" File Exists ",
+			fmt.Sprintf("%s already exists in the project.\nBack it up to %s.bak and apply?", item.Name, item.Name),
+			func() { a.backupAndApply(cat, item) })
+		return
+	}
+
+	a.confirmApplyDir(item, func() {
+		if a.applyItem(cat, item) && a.followToggle {
+			a.selectItem(item.GlobalPath)
+		}
+	})
+}
+
+// confirmApplyDir runs apply at once for files, or when confirm_dirs is off.
+// Otherwise it first shows the directory item's tree and total size and
+// asks whether to apply it.
+func (a *App) confirmApplyDir(item Item, apply func()) {
+	if !a.confirmDirs || !item.IsDir {
+		apply()
+		return
+	}
+	files, size := dirStats(item.GlobalPath)
+	var b strings.Builder
+	fmt.Fprintf(&b, "[cyan::b]%s/[-:-:-]  [darkgray]%d %s, %s in total[-]\n\n",
+		tview.Escape(item.Name), files, plural(files, "file", "files"), formatSize(int(size)))
+	a.buildTree(&b, a.newTreeIgnore(item.GlobalPath), item.GlobalPath, "", 0, defaultTreeDepth)
+	b.WriteString("\n[green]y[-] apply    [red]n[-] cancel    [darkgray]j/k scroll[-]")
+
+	a.confirmOpen = true
+	a.confirmAction = apply
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(b.String())
+	view.SetBorder(true).
+		SetTitle(" Apply Directory ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	width, height := a.treeModalSize()
+	a.pages.AddPage("confirm", modal(view, width, min(height, strings.Count(b.String(), "\n")+3)), true, true)
+	a.app.SetFocus(view)
+}
+
+// dirStats counts the files under dir at any depth, hidden ones included,
+// and totals their size: everything that applying dir makes visible.
+func dirStats(dir string) (files int, size int64) {
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files++
+			size += info.Size()
+		}
+		return nil
+	})
+	return files, size
+}
+
+// applySelectedAs prompts for a project name and applies the selected
+// available item under that name.
+func (a *App) applySelectedAs() {
+	if a.blockedByReadOnly() || a.currentPanelIdx != 0 {
+		return
+	}
+	idx := a.availableIndex()
+	if idx < 0 {
+		return
+	}
+
+	cat := a.categories[a.activeTabIdx]
+	item := a.availableItems[idx]
+	if cat.Strategy != StrategySymlink {
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]Custom names need the symlink strategy (%s uses %s)[-]", cat.Name, cat.Strategy))
+		return
+	}
+
+	a.showPrompt(" Apply As ", "Name: ", item.Name, func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "." || name == ".." || strings.ContainsRune(name, filepath.Separator) {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] invalid name %q", name))
+			return
+		}
+		if _, err := os.Lstat(filepath.Join(cat.ProjectDir, name)); err == nil {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %s already exists in the project", name))
+			return
+		}
+		if name != item.Name {
+			item.LinkName = name
+		}
+		a.confirmApplyDir(item, func() { a.applyItem(cat, item) })
+	})
+}
+
+// applyItem links item and records it for undo. It reports whether the
+// symlink was created.
+func (a *App) applyItem(cat Category, item Item) bool {
+	if err := a.linkItem(cat, item); err != nil {
+		a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
+		return false
+	}
+
+	a.lastAction = &Action{Kind: ActionApply, Category: cat, Items: []Item{item}}
+	a.recordHistory(ActionApply, cat, item)
+	a.noteUsage(cat, item)
+	a.refreshAll()
+	return true
+}
+
+// backupAndApply renames an existing project entry to <name>.bak before
+// applying item in its place.
+func (a *App) backupAndApply(cat Category, item Item) {
+	target := filepath.Join(cat.ProjectDir, item.Name)
+	backup := target + ".bak"
+	if _, err := os.Lstat(backup); err == nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %s already exists", backup))
+		return
+	}
+	if err := os.Rename(target, backup); err != nil {
+		a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
+		return
+	}
+
+	if a.applyItem(cat, item) {
+		a.statusBar.SetText(fmt.Sprintf(" Backed up existing %s to %s.bak and applied", item.Name, item.Name))
+	}
+}
+
+func (a *App) removeSelected() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	idx := a.appliedList.GetCurrentItem()
+	if idx < 0 || idx >= len(a.appliedItems) {
+		return
+	}
+
+	cat := a.categories[a.activeTabIdx]
+	item := a.appliedItems[idx]
+
+	if err := a.unlinkItem(cat, item); err != nil {
+		a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
+		return
+	}
+
+	a.lastAction = &Action{Kind: ActionRemove, Category: cat, Items: []Item{item}}
+	a.recordHistory(ActionRemove, cat, item)
+	a.refreshAll()
+	if a.followToggle {
+		a.selectItem(item.GlobalPath)
+	}
+}
+
+// describeFSError turns an apply or remove failure into a message that says
+// what went wrong and what to try, for the common filesystem failures.
+func describeFSError(err error) string {
+	path := ""
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	switch {
+	case errors.As(err, &linkErr):
+		path = linkErr.New
+	case errors.As(err, &pathErr):
+		path = pathErr.Path
+	}
+	if path == "" {
+		return err.Error()
+	}
+
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Sprintf("permission denied on %s — project dir not writable, check permissions", path)
+	case errors.Is(err, syscall.EROFS):
+		return fmt.Sprintf("%s is on a read-only filesystem — remount it writable or use --read-only", path)
+	case errors.Is(err, fs.ErrExist):
+		return fmt.Sprintf("%s already exists — move it aside or remove it first", path)
+	case errors.Is(err, syscall.EXDEV):
+		return fmt.Sprintf("cannot move %s across filesystems — the store and project are on different devices", path)
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Sprintf("%s does not exist — it may have been moved or deleted; refresh and retry", path)
+	}
+	return err.Error()
+}
+
+// linkItem applies item to the project using the category's strategy.
+func (a *App) linkItem(cat Category, item Item) error {
+	if appliesOntoItself(cat, item) {
+		return errSelfApply
+	}
+	if err := os.MkdirAll(cat.ProjectDir, 0755); err != nil {
+		return err
+	}
+	var err error
+	switch cat.Strategy {
+	case StrategyCopy:
+		err = applyCopy(cat, item)
+	case StrategyMerge:
+		err = a.applyMerge(cat, item)
+	case StrategyHardlink:
+		err = applyHardlink(cat, item)
+	default:
+		err = a.applySymlink(cat, item)
+	}
+	if err == nil {
+		a.logOperation(cat, "apply", item)
+	}
+	return err
+}
+
+// errSelfApply is returned when an item's project path is the global item.
+var errSelfApply = errors.New("the project directory resolves to the global store, so the item would be applied onto itself")
+
+// appliesOntoItself reports whether applying item would target the global
+// item itself, as when lazyclaude is pointed at its own store.
+func appliesOntoItself(cat Category, item Item) bool {
+	target := filepath.Join(canonicalPath(cat.ProjectDir), item.linkName())
+	return target == filepath.Join(canonicalPath(filepath.Dir(item.GlobalPath)), filepath.Base(item.GlobalPath))
+}
+
+// unlinkItem reverses linkItem for item using the category's strategy.
+func (a *App) unlinkItem(cat Category, item Item) error {
+	if item.Warning != "" {
+		return fmt.Errorf("%s %s; remove it yourself if it should go", filepath.Join(cat.ProjectDir, item.Name), item.Warning)
+	}
+	var err error
+	switch cat.Strategy {
+	case StrategyCopy:
+		err = removeCopy(cat, item)
+	case StrategyHardlink:
+		err = removeHardlinks(filepath.Join(cat.ProjectDir, item.Name), canonicalPath(item.GlobalPath))
+	case StrategyMerge:
+		err = removeMerge(cat, item)
+	default:
+		err = os.Remove(filepath.Join(cat.ProjectDir, item.linkName()))
+	}
+	if err == nil {
+		a.logOperation(cat, "remove", item)
+	}
+	return err
+}
+
+const (
+	historyLogName = ".lazyclaude-history.log"
+
+	// maxHistoryLogBytes caps the log; a full log is moved to
+	// historyLogName+".1", replacing the previous one, and a new one begun.
+	maxHistoryLogBytes = 256 << 10
+)
+
+// logOperation appends a tab-separated line (time, action, category, item)
+// for an apply or remove to the history log in the project's claude_dir.
+// The log is only a record, so failing to write it is not reported.
+func (a *App) logOperation(cat Category, action string, item Item) {
+	if !a.historyLog {
+		return
+	}
+	path := filepath.Join(cat.claudeDir(), historyLogName)
+	if info, err := os.Stat(path); err == nil && info.Size() >= maxHistoryLogBytes {
+		os.Rename(path, path+".1")
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	name := item.Name
+	if link := item.linkName(); link != item.Name {
+		name += " as " + link
+	}
+	fmt.Fprintf(f, "%s\t%s\t%s\t%s\n", time.Now().Format(time.RFC3339), action, cat.Name, name)
+}
+
+// isApplied reports whether item is applied to the project under the
+// category's strategy.
+func isApplied(cat Category, item Item) bool {
+	projectPath := filepath.Join(cat.ProjectDir, item.Name)
+	switch cat.Strategy {
+	case StrategyCopy:
+		info, err := os.Lstat(projectPath)
+		return err == nil && info.Mode()&os.ModeSymlink == 0 && loadCopies(cat.claudeDir())[copyKey(cat, item)]
+	case StrategyHardlink:
+		return isHardlinked(projectPath, canonicalPath(item.GlobalPath))
+	case StrategyMerge:
+		if !item.IsDir {
+			return isAppliedSymlink(projectPath, item.GlobalPath)
+		}
+		entries, err := os.ReadDir(item.GlobalPath)
+		if err != nil {
+			return false
+		}
+		linked := 0
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			if !isAppliedSymlink(filepath.Join(projectPath, entry.Name()), filepath.Join(item.GlobalPath, entry.Name())) {
+				return false
+			}
+			linked++
+		}
+		return linked > 0
+	default:
+		return isAppliedSymlink(projectPath, item.GlobalPath)
+	}
+}
+
+// applySymlink links the project path to the global item.
+func (a *App) applySymlink(cat Category, item Item) error {
+	link := filepath.Join(cat.ProjectDir, item.linkName())
+	return os.Symlink(a.storeLinkTarget(item.GlobalPath, link), link)
+}
+
+// storeLinkTarget returns what a project link at link to the store entry
+// src should contain: src itself, or for an entry of the project store a
+// path relative to the link, so the link survives moving or cloning the
+// project.
+func (a *App) storeLinkTarget(src, link string) string {
+	if a.projectStore == "" || !insideAny(src, []string{a.projectStore}) {
+		return src
+	}
+	dir := canonicalPath(filepath.Dir(link))
+	if rel, err := filepath.Rel(dir, filepath.Join(canonicalPath(filepath.Dir(src)), filepath.Base(src))); err == nil {
+		return rel
+	}
+	return src
+}
+
+// applyCopy copies the global item (file or directory tree) into the project.
+func applyCopy(cat Category, item Item) error {
+	target := filepath.Join(cat.ProjectDir, item.Name)
+	if _, err := os.Lstat(target); err == nil {
+		return fmt.Errorf("%s already exists", target)
+	}
+	if err := copyPath(item.GlobalPath, target); err != nil {
+		return err
+	}
+	if err := recordCopy(cat, item, true); err != nil {
+		// An unrecorded copy could never be removed again.
+		os.RemoveAll(target)
+		return err
+	}
+	return nil
+}
+
+// removeCopy deletes a copy made by applyCopy. Entries the copy manifest
+// doesn't list are the user's own and are never deleted.
+func removeCopy(cat Category, item Item) error {
+	target := filepath.Join(cat.ProjectDir, item.Name)
+	if !loadCopies(cat.claudeDir())[copyKey(cat, item)] {
+		return fmt.Errorf("%s was not copied there by lazyclaude; remove it yourself if it should go", target)
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return err
+	}
+	return recordCopy(cat, item, false)
+}
+
+// copyManifestName is the file in claude_dir listing the copies applyCopy
+// made, by path relative to claude_dir. Only listed entries count as applied
+// copies, so a file of the user's that shares an item's name is left alone.
+const copyManifestName = ".lazyclaude-copies.json"
+
+// copyKey is item's entry in the copy manifest of cat's claude_dir.
+func copyKey(cat Category, item Item) string {
+	rel, err := filepath.Rel(cat.claudeDir(), filepath.Join(cat.ProjectDir, item.Name))
+	if err != nil {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// loadCopies reads the copy manifest in claudeDir as a set. A missing or
+// unreadable manifest lists nothing.
+func loadCopies(claudeDir string) map[string]bool {
+	copies := map[string]bool{}
+	data, err := os.ReadFile(filepath.Join(claudeDir, copyManifestName))
+	if err != nil {
+		return copies
+	}
+	var paths []string
+	if json.Unmarshal(data, &paths) == nil {
+		for _, path := range paths {
+			copies[path] = true
+		}
+	}
+	return copies
+}
+
+// recordCopy adds item to, or removes it from, the copy manifest. The
+// manifest is deleted once it lists nothing.
+func recordCopy(cat Category, item Item, copied bool) error {
+	dir := cat.claudeDir()
+	copies := loadCopies(dir)
+	if copied {
+		copies[copyKey(cat, item)] = true
+	} else {
+		delete(copies, copyKey(cat, item))
+	}
+
+	path := filepath.Join(dir, copyManifestName)
+	if len(copies) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	paths := make([]string, 0, len(copies))
+	for path := range copies {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	data, err := json.MarshalIndent(paths, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// applyHardlink hard-links the global item (file or directory tree) into the
+// project, so edits show up on both sides. Hard links cannot cross
+// filesystems; a partly linked tree is removed again on failure.
+func applyHardlink(cat Category, item Item) error {
+	target := filepath.Join(cat.ProjectDir, item.Name)
+	if _, err := os.Lstat(target); err == nil {
+		return fmt.Errorf("%s already exists", target)
+	}
+	err := hardlinkPath(canonicalPath(item.GlobalPath), target)
+	if err == nil {
+		return nil
+	}
+	os.RemoveAll(target)
+	if errors.Is(err, syscall.EXDEV) {
+		return fmt.Errorf("cannot hardlink %s: the store and project are on different filesystems — use the copy or symlink strategy for %s", item.Name, cat.Name)
+	}
+	return err
+}
+
+// errNotHardlinked stops the walk in isHardlinked at the first file that
+// is not linked.
+var errNotHardlinked = errors.New("not hardlinked")
+
+// isHardlinked reports whether projectPath mirrors globalPath with hard
+// links: the same file (same device and inode), or for a directory, every
+// file in it.
+func isHardlinked(projectPath, globalPath string) bool {
+	linked := false
+	err := filepath.WalkDir(globalPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(globalPath, path)
+		if err != nil {
+			return err
+		}
+		src, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		dst, err := os.Stat(filepath.Join(projectPath, rel))
+		if err != nil || !os.SameFile(src, dst) {
+			return errNotHardlinked
+		}
+		linked = true
+		return nil
+	})
+	return err == nil && linked
+}
+
+// removeHardlinks removes what applyHardlink created at projectPath: files
+// that are the same file as their global counterpart and the recreated
+// symlinks, then any directories left empty. Anything the user added or
+// replaced stays, and an item that isn't hard-linked at all is refused.
+func removeHardlinks(projectPath, globalPath string) error {
+	if !isHardlinked(projectPath, globalPath) {
+		return fmt.Errorf("%s is not hard-linked to the store; remove it yourself if it should go", projectPath)
+	}
+	var dirs []string
+	err := filepath.WalkDir(globalPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(globalPath, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(projectPath, rel)
+		switch {
+		case d.IsDir():
+			dirs = append(dirs, dst)
+		case d.Type()&os.ModeSymlink != 0:
+			src, _ := os.Readlink(path)
+			if link, err := os.Readlink(dst); err == nil && link == src {
+				return os.Remove(dst)
+			}
+		default:
+			src, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			if info, err := os.Lstat(dst); err == nil && os.SameFile(src, info) {
+				return os.Remove(dst)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i]) // fails harmlessly if the user keeps other files there
+	}
+	return nil
+}
+
+// applyMerge links each entry of a directory item into a same-named project
+// directory, leaving any other files in that directory untouched. File items
+// are symlinked as usual.
+func (a *App) applyMerge(cat Category, item Item) error {
+	if !item.IsDir {
+		return a.applySymlink(cat, item)
+	}
+
+	entries, err := os.ReadDir(item.GlobalPath)
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(cat.ProjectDir, item.Name)
+
+	// Check for conflicts up front so a failed merge leaves nothing behind.
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		dst := filepath.Join(target, entry.Name())
+		if _, err := os.Lstat(dst); err == nil && !isAppliedSymlink(dst, filepath.Join(item.GlobalPath, entry.Name())) {
+			return fmt.Errorf("cannot merge: %s already exists", dst)
+		}
+	}
+
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		src := filepath.Join(item.GlobalPath, entry.Name())
+		dst := filepath.Join(target, entry.Name())
+		if isAppliedSymlink(dst, src) {
+			continue
+		}
+		if err := os.Symlink(a.storeLinkTarget(src, dst), dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeMerge removes the links created by applyMerge, then the project
+// directory itself if nothing else is left in it.
+func removeMerge(cat Category, item Item) error {
+	target := filepath.Join(cat.ProjectDir, item.Name)
+	if !item.IsDir {
+		return os.Remove(target)
+	}
+
+	entries, err := os.ReadDir(item.GlobalPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		src := filepath.Join(item.GlobalPath, entry.Name())
+		dst := filepath.Join(target, entry.Name())
+		if isAppliedSymlink(dst, src) {
+			if err := os.Remove(dst); err != nil {
+				return err
+			}
+		}
+	}
+	os.Remove(target) // fails harmlessly if the user keeps other files there
+	return nil
+}
+
+// copyPath recursively copies src to dst, preserving file modes and
+// recreating symlinks as symlinks.
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		out := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(out, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, out)
+		default:
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(out, data, info.Mode().Perm())
+		}
+	})
+}
+
+// hardlinkPath mirrors src at dst: directories are created, files are hard
+// linked, and symlinks are recreated as in copyPath.
+func hardlinkPath(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		out := filepath.Join(dst, rel)
+
+		switch {
+		case d.IsDir():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return os.MkdirAll(out, info.Mode().Perm())
+		case d.Type()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, out)
+		default:
+			return os.Link(path, out)
+		}
+	})
+}
+
+// --- Export ---
+
+// exportBundle copies every applied item, with symlinks resolved to real
+// files, into a timestamped directory next to the project's .claude
+// directory, laid out by category, so it can be shared without the stores.
+func (a *App) exportBundle() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	bundle := filepath.Join(filepath.Dir(a.claudeDir), "lazyclaude-bundle-"+time.Now().Format("20060102-150405"))
+
+	count := 0
+	for _, cat := range a.categories {
+		if linkedCategory(cat) {
+			// The whole category is applied: export all of it.
+			if err := copyResolved(cat.ProjectDir, filepath.Join(bundle, cat.Name), map[string]bool{}); err != nil {
+				a.statusBar.SetText(" [red]Error:[-] exporting: " + tview.Escape(describeFSError(err)))
+				return
+			}
+			count += len(visibleEntries(cat.ProjectDir))
+			continue
+		}
+		_, applied := scanCategory(cat, a.primaryDocs)
+		for _, item := range applied {
+			if item.Warning != "" {
+				continue
+			}
+			src := filepath.Join(cat.ProjectDir, item.linkName())
+			dst := filepath.Join(bundle, cat.Name, item.linkName())
+			if err := copyResolved(src, dst, map[string]bool{}); err != nil {
+				a.statusBar.SetText(" [red]Error:[-] exporting: " + tview.Escape(describeFSError(err)))
+				return
+			}
+			count++
+		}
+	}
+	if count == 0 {
+		a.statusBar.SetText(" [yellow]Nothing applied to export[-]")
+		return
+	}
+	a.statusBar.SetText(fmt.Sprintf(" Exported %d %s to %s", count, plural(count, "item", "items"), tview.Escape(bundle)))
+}
+
+// copyResolved copies src to dst, following symlinks so the copy holds real
+// files. Broken links are skipped, as are links back into a directory that
+// is being copied. visiting tracks those directories.
+func copyResolved(src, dst string, visiting map[string]bool) error {
+	real, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(real)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(real)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dst, data, info.Mode().Perm())
+	}
+
+	if visiting[real] {
+		return nil
+	}
+	visiting[real] = true
+	defer delete(visiting, real)
+	if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(real)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		err := copyResolved(filepath.Join(real, entry.Name()), filepath.Join(dst, entry.Name()), visiting)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// --- Favorites ---
+
+// isFavorite reports whether item is starred in cat.
+func (a *App) isFavorite(cat Category, item Item) bool {
+	for _, name := range a.favorites[cat.Name] {
+		if name == item.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortItems orders items by name in the current sort direction. Items of the
+// same name from several stores stay in store order either way.
+func (a *App) sortItems(items []Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if a.ascending {
+			return items[i].Name < items[j].Name
+		}
+		return items[i].Name > items[j].Name
+	})
+}
+
+// toggleSortDirection reverses the order of both lists.
+func (a *App) toggleSortDirection() {
+	a.ascending = !a.ascending
+	a.sortAvailable(a.categories[a.activeTabIdx], a.availableItems)
+	a.sortItems(a.appliedItems)
+	a.renderAll()
+}
+
+// sortAvailable orders the Available list: by name, then by usage when
+// SortByUsage is set, with starred items first.
+func (a *App) sortAvailable(cat Category, items []Item) {
+	a.sortItems(items)
+	if a.sortOrder == SortByUsage {
+		sort.SliceStable(items, func(i, j int) bool {
+			return a.usage.count(cat.Name, items[i].Name) > a.usage.count(cat.Name, items[j].Name)
+		})
+	}
+	a.sortFavoritesFirst(cat, items)
+}
+
+// toggleUsageSort switches the Available list between name and usage order
+// and saves the choice to the config file.
+func (a *App) toggleUsageSort() {
+	if a.sortOrder == SortByUsage {
+		a.sortOrder = SortByName
+	} else {
+		a.sortOrder = SortByUsage
+	}
+	a.sortAvailable(a.categories[a.activeTabIdx], a.availableItems)
+	a.renderAll()
+	if err := setConfigValue("sort", string(a.sortOrder)); err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] saving sort order: %v", err))
+		return
+	}
+	a.statusBar.SetText(fmt.Sprintf(" Available sorted by [green]%s[-]", a.sortOrder))
+}
+
+// noteUsage records items as applied in cat for the usage sort. A failure
+// only leaves the sort without data, so it is not reported.
+func (a *App) noteUsage(cat Category, items ...Item) {
+	if usage, err := recordUsage(cat, items...); err == nil {
+		a.usage = usage
+	}
+}
+
+// sortFavoritesFirst moves starred items to the front, keeping name order
+// within each group.
+func (a *App) sortFavoritesFirst(cat Category, items []Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return a.isFavorite(cat, items[i]) && !a.isFavorite(cat, items[j])
+	})
+}
+
+// toggleFavorite stars or unstars the selected item and persists the change.
+func (a *App) toggleFavorite() {
+	item := a.selectedItem()
+	if item == nil || a.blockedByReadOnly() {
+		return
+	}
+	cat := a.categories[a.activeTabIdx]
+
+	names := a.favorites[cat.Name]
+	starred := !a.isFavorite(cat, *item)
+	if starred {
+		names = append(names, item.Name)
+		sort.Strings(names)
+	} else {
+		for i, name := range names {
+			if name == item.Name {
+				names = append(names[:i], names[i+1:]...)
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		delete(a.favorites, cat.Name)
+	} else {
+		a.favorites[cat.Name] = names
+	}
+
+	if err := saveFavorites(a.favorites); err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %v", err))
+		return
+	}
+
+	name := item.DisplayName()
+	a.refreshAll()
+	if starred {
+		a.statusBar.SetText(fmt.Sprintf(" Starred %s", name))
+	} else {
+		a.statusBar.SetText(fmt.Sprintf(" Unstarred %s", name))
+	}
+}
+
+// applyFavorites applies every starred item still available in the active
+// category.
+func (a *App) applyFavorites() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	cat := a.categories[a.activeTabIdx]
+	var items []Item
+	for _, item := range a.availableItems {
+		if a.isFavorite(cat, item) {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		a.statusBar.SetText(" [yellow]No favorites to apply[-]")
+		return
+	}
+	a.confirm("applyFavorites", " Apply Starred ",
+		fmt.Sprintf("Apply %d starred %s?", len(items), cat.Name),
+		func() { a.bulkToggle(ActionApply, items, a.linkItem, "Applied") })
+}
+
+// reviewFavorites shows the starred items of the active category, applied
+// or not, one after another in a single scrollable modal. Each file is
+// highlighted on its own and headed by a separator naming it.
+func (a *App) reviewFavorites() {
+	cat := a.categories[a.activeTabIdx]
+	var items []Item
+	for _, item := range append(append([]Item{}, a.availableItems...), a.appliedItems...) {
+		if a.isFavorite(cat, item) {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		a.statusBar.SetText(" [yellow]Star items with * to review them together[-]")
+		return
+	}
+
+	var b strings.Builder
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		path := item.GlobalPath
+		if item.IsDir {
+			path = primaryDoc(path, a.primaryDocs)
+		}
+		header := item.DisplayName()
+		if path != "" && path != item.GlobalPath {
+			header += "/" + filepath.Base(path)
+		}
+		fmt.Fprintf(&b, "[green::b]━━ %s ━━[-::-]\n\n", tview.Escape(header))
+		b.WriteString(a.reviewBody(path))
+		b.WriteString("\n")
+	}
+	a.reviewOpen = true
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetScrollable(true).
+		SetText(b.String())
+	view.SetBackgroundColor(a.previewBackground())
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
+			a.closeReview()
+			return nil
+		}
+		return event
+	})
+	view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Starred %s (%d) — [j/k] scroll  [esc] close ", cat.Name, len(items))).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	a.pages.AddPage("review", modal(view, a.screenWidth-4, a.screenHeight-2), true, true)
+	a.app.SetFocus(view)
+}
+
+// reviewBody returns the highlighted contents of path for reviewFavorites,
+// or a dim note when there is nothing readable to show.
+func (a *App) reviewBody(path string) string {
+	if path == "" {
+		return "[darkgray](no " + tview.Escape(strings.Join(a.primaryDocs, " or ")) + ")[-]\n"
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "[red]" + tview.Escape(describeFSError(err)) + "[-]\n"
+	}
+	if info.Size() > maxCopyBytes {
+		return fmt.Sprintf("[darkgray](%s — too large to show)[-]\n", formatSize(int(info.Size())))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "[red]" + tview.Escape(describeFSError(err)) + "[-]\n"
+	}
+	if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+		return "[darkgray](binary file)[-]\n"
+	}
+	return a.highlightCode(string(data), detectLanguage(path))
+}
+
+func (a *App) closeReview() {
+	a.reviewOpen = false
+	a.pages.RemovePage("review")
+	a.restoreFocus()
+	a.updateBorderColors()
+}
+
+// --- Undo ---
+
+// undo reverses the last apply/remove. The action is cleared afterwards so a
+// second undo is a no-op.
+func (a *App) undo() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	if a.lastAction == nil {
+		a.statusBar.SetText(" [yellow]Nothing to undo[-]")
+		return
+	}
+
+	action := a.lastAction
+	a.lastAction = nil
+
+	if action.WholeCategory {
+		undoFn, verb := a.unlinkCategory, "Undid link of"
+		if action.Kind == ActionRemove {
+			undoFn, verb = a.linkCategory, "Undid unlink of"
+		}
+		if err := undoFn(action.Category); err != nil {
+			a.statusBar.SetText(" [red]Undo failed:[-] " + tview.Escape(describeFSError(err)))
+			return
+		}
+		a.refreshAll()
+		a.statusBar.SetText(fmt.Sprintf(" %s %s", verb, action.Category.Name))
+		return
+	}
+
+	undoFn, verb := a.unlinkItem, "Undid apply of"
+	if action.Kind == ActionRemove {
+		undoFn, verb = a.linkItem, "Undid remove of"
+	}
+
+	var failed int
+	var lastErr error
+	for _, item := range action.Items {
+		if err := undoFn(action.Category, item); err != nil {
+			failed++
+			lastErr = err
+		}
+	}
+
+	a.refreshAll()
+	if failed == len(action.Items) {
+		a.statusBar.SetText(" [red]Undo failed:[-] " + tview.Escape(describeFSError(lastErr)))
+		return
+	}
+
+	what := fmt.Sprintf("%s/%s", action.Category.Name, action.Items[0].DisplayName())
+	if len(action.Items) > 1 {
+		what = fmt.Sprintf("%d %s items", len(action.Items), action.Category.Name)
+	}
+	msg := fmt.Sprintf(" %s %s", verb, what)
+	if failed > 0 {
+		msg += fmt.Sprintf(" [red](%d failed: %s)[-]", failed, tview.Escape(describeFSError(lastErr)))
+	}
+	a.statusBar.SetText(msg)
+}
+
+// --- Session history ---
+
+// maxHistory bounds how many applies and removes the history modal keeps.
+const maxHistory = 50
+
+// historyEntry is one apply or remove made this session, or one bulk
+// operation's worth of them.
+type historyEntry struct {
+	Kind          ActionKind
+	Category      string
+	Item          Item
+	WholeCategory bool           // the category was linked or unlinked as a directory; Item is unset
+	Batch         []historyEntry // the items of a bulk apply or remove; Category and Item are unset
+	At            time.Time
+}
+
+func (a *App) recordHistory(kind ActionKind, cat Category, item Item) {
+	a.addHistory(historyEntry{Kind: kind, Category: cat.Name, Item: item, At: time.Now()})
+}
+
+// recordBatch adds a single history entry for the items a bulk operation
+// applied or removed, each given as an entry of its own.
+func (a *App) recordBatch(kind ActionKind, batch []historyEntry) {
+	switch len(batch) {
+	case 0:
+		return
+	case 1:
+		entry := batch[0]
+		entry.At = time.Now()
+		a.addHistory(entry)
+	default:
+		a.addHistory(historyEntry{Kind: kind, Batch: batch, At: time.Now()})
+	}
+}
+
+// addHistory appends entry to the session history, dropping the oldest
+// entries beyond maxHistory.
+func (a *App) addHistory(entry historyEntry) {
+	a.history = append(a.history, entry)
+	if len(a.history) > maxHistory {
+		a.history = a.history[len(a.history)-maxHistory:]
+	}
+}
+
+// showHistory lists this session's applies and removes, newest first. Enter
+// jumps to the item; Space jumps to it and toggles it again.
+func (a *App) showHistory() {
+	if len(a.history) == 0 {
+		a.statusBar.SetText(" [yellow]Nothing applied or removed this session[-]")
+		return
+	}
+	a.historyOpen = true
+
+	entries := make([]historyEntry, len(a.history))
+	for i, entry := range a.history {
+		entries[len(entries)-1-i] = entry
+	}
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedBackgroundColor(a.selectionColor).
+		SetSelectedTextColor(tcell.ColorWhite)
+	for _, entry := range entries {
+		verb := "[green]applied[-]"
+		if entry.Kind == ActionRemove {
+			verb = "[red]removed[-]"
+		}
+		what := tview.Escape(entry.Category) + "/" + tview.Escape(entry.Item.DisplayName())
+		switch {
+		case entry.WholeCategory:
+			what = tview.Escape(entry.Category) + " as a directory"
+		case len(entry.Batch) > 0:
+			what = batchSummary(entry.Batch)
+		}
+		list.AddItem(fmt.Sprintf("%s  %s  %s", entry.At.Format("15:04"), verb, what), "", 0, nil)
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		entry := entries[list.GetCurrentItem()]
+		switch {
+		case event.Key() == tcell.KeyEnter:
+			a.closeHistory()
+			a.jumpToEntry(entry)
+		case event.Rune() == ' ':
+			a.closeHistory()
+			if len(entry.Batch) > 0 {
+				a.toggleBatch(entry)
+				break
+			}
+			if !a.jumpToEntry(entry) {
+				break
+			}
+			if entry.WholeCategory {
+				a.toggleCategoryLink()
+			} else {
+				a.toggleSelected()
+			}
+		case event.Rune() == 'j':
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case event.Rune() == 'k':
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+			a.closeHistory()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[darkgray]Enter go to, Space toggle again, Esc close[-]")
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, true).
+		AddItem(hint, 1, 0, false)
+	layout.SetBorder(true).
+		SetTitle(" This Session ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	a.pages.AddPage("history", modal(layout, 60, min(len(entries)+3, 20)), true, true)
+	a.app.SetFocus(list)
+}
+
+// batchSummary describes the items of a batch history entry, naming their
+// category when they share one.
+func batchSummary(batch []historyEntry) string {
+	for _, entry := range batch {
+		if entry.Category != batch[0].Category {
+			return fmt.Sprintf("%d items", len(batch))
+		}
+	}
+	return fmt.Sprintf("%d %s items", len(batch), tview.Escape(batch[0].Category))
+}
+
+// toggleBatch toggles the items of a batch history entry again: those it
+// applied that are still applied are removed, or those it removed that are
+// still available are applied. The result is recorded as a new batch.
+func (a *App) toggleBatch(entry historyEntry) {
+	if a.blockedByReadOnly() {
+		return
+	}
+	kind, fn, verb := ActionRemove, a.unlinkItem, "Removed"
+	if entry.Kind == ActionRemove {
+		kind, fn, verb = ActionApply, a.linkItem, "Applied"
+	}
+
+	candidates := map[string][]Item{} // category name → items fn applies to
+	var done []historyEntry
+	var lastErr error
+	for _, member := range entry.Batch {
+		cat, ok := a.findCategory(member.Category)
+		if !ok {
+			continue
+		}
+		items, scanned := candidates[cat.Name]
+		if !scanned {
+			available, applied := scanCategory(cat, a.primaryDocs)
+			items = removableItems(applied)
+			if kind == ActionApply {
+				items = available
+			}
+			candidates[cat.Name] = items
+		}
+		for _, item := range items {
+			if item.GlobalPath != member.Item.GlobalPath {
+				continue
+			}
+			if err := fn(cat, item); err != nil {
+				lastErr = err
+				break
+			}
+			if kind == ActionApply {
+				a.noteUsage(cat, item)
+			}
+			done = append(done, historyEntry{Kind: kind, Category: cat.Name, Item: item})
+			break
+		}
+	}
+	a.recordBatch(kind, done)
+
+	a.refreshAll()
+	msg := fmt.Sprintf(" %s %d items", verb, len(done))
+	if skipped := len(entry.Batch) - len(done); skipped > 0 {
+		reason := "no longer there"
+		if lastErr != nil {
+			reason = describeFSError(lastErr)
+		}
+		msg += fmt.Sprintf(" [red](%d skipped: %s)[-]", skipped, tview.Escape(reason))
+	}
+	a.statusBar.SetText(msg)
+}
+
+func (a *App) closeHistory() {
+	a.historyOpen = false
+	a.pages.RemovePage("history")
+	a.restoreFocus()
+	a.updateBorderColors()
+}
+
+// jumpToEntry switches to the category of a history entry and selects its
+// item in whichever list now holds it; for a batch, its first item. It
+// reports whether the item was found.
+func (a *App) jumpToEntry(entry historyEntry) bool {
+	if len(entry.Batch) > 0 {
+		entry = entry.Batch[0]
+	}
+	tab := -1
+	for i, cat := range a.categories {
+		if cat.Name == entry.Category {
+			tab = i
+			break
+		}
+	}
+	if tab < 0 {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] category %s no longer exists", tview.Escape(entry.Category)))
+		return false
+	}
+	a.activeTabIdx = tab
+	a.refreshAll()
+
+	if entry.WholeCategory || a.selectItem(entry.Item.GlobalPath) {
+		return true
+	}
+	a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %s/%s no longer exists", tview.Escape(entry.Category), tview.Escape(entry.Item.DisplayName())))
+	return false
+}
+
+// selectItem focuses whichever list holds the item with globalPath in the
+// active category and moves the cursor to it. It reports whether it was found.
+func (a *App) selectItem(globalPath string) bool {
+	for i, item := range a.appliedItems {
+		if item.GlobalPath == globalPath {
+			a.focusPanel(1)
+			a.appliedList.SetCurrentItem(i)
+			a.updatePreview()
+			return true
+		}
+	}
+	for row, idx := range a.availableRows {
+		if idx >= 0 && a.availableItems[idx].GlobalPath == globalPath {
+			a.focusPanel(0)
+			a.availableList.SetCurrentItem(row)
+			a.updatePreview()
+			return true
+		}
+	}
+	return false
+}
+
+// --- Whole-category links ---
+
+// toggleCategoryLink links the active category's global directory into the
+// project as a single directory symlink, or removes that link.
+func (a *App) toggleCategoryLink() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	cat := a.categories[a.activeTabIdx]
+	if cat.Root {
+		a.statusBar.SetText(" [yellow]Root files are applied one at a time[-]")
+		return
+	}
+
+	if linkedCategory(cat) {
+		a.confirm("linkCategory", " Unlink Category ",
+			fmt.Sprintf("Remove the %s directory link from the project?", cat.Name),
+			func() {
+				if err := a.unlinkCategory(cat); err != nil {
+					a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
+					return
+				}
+				a.lastAction = &Action{Kind: ActionRemove, Category: cat, WholeCategory: true}
+				a.addHistory(historyEntry{Kind: ActionRemove, Category: cat.Name, WholeCategory: true, At: time.Now()})
+				a.refreshAll()
+				a.statusBar.SetText(fmt.Sprintf(" Unlinked %s", cat.Name))
+			})
+		return
+	}
+
+	if len(cat.GlobalDirs) > 1 {
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]%s spans several stores and cannot be linked as one directory[-]", cat.Name))
+		return
+	}
+	if info, err := os.Lstat(cat.ProjectDir); err == nil {
+		entries, _ := os.ReadDir(cat.ProjectDir)
+		if info.Mode()&os.ModeSymlink != 0 || !info.IsDir() || len(entries) > 0 {
+			a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %s already exists — remove its items first", tview.Escape(cat.ProjectDir)))
+			return
+		}
+		// An empty directory is replaced by the link.
+		if err := os.Remove(cat.ProjectDir); err != nil {
+			a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
+			return
+		}
+	}
+
+	if err := a.linkCategory(cat); err != nil {
+		a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
+		return
+	}
+	a.lastAction = &Action{Kind: ActionApply, Category: cat, WholeCategory: true}
+	a.addHistory(historyEntry{Kind: ActionApply, Category: cat.Name, WholeCategory: true, At: time.Now()})
+	a.refreshAll()
+	a.statusBar.SetText(fmt.Sprintf(" Linked %s as a directory", cat.Name))
+}
+
+// linkCategory creates cat's project directory as a symlink to its only
+// global directory. The history log records it with the item "*".
+func (a *App) linkCategory(cat Category) error {
+	if err := os.MkdirAll(filepath.Dir(cat.ProjectDir), 0755); err != nil {
+		return err
+	}
+	target := a.storeLinkTarget(cat.GlobalDirs[0].Path, cat.ProjectDir)
+	if err := os.Symlink(target, cat.ProjectDir); err != nil {
+		return err
+	}
+	a.logOperation(cat, "apply", Item{Name: "*"})
+	return nil
+}
+
+// unlinkCategory removes the directory link made by linkCategory.
+func (a *App) unlinkCategory(cat Category) error {
+	if !linkedCategory(cat) {
+		return fmt.Errorf("%s is not linked to the store as a directory", cat.ProjectDir)
+	}
+	if err := os.Remove(cat.ProjectDir); err != nil {
+		return err
+	}
+	a.logOperation(cat, "remove", Item{Name: "*"})
+	return nil
+}
+
+// --- Bulk apply/remove ---
+
+func (a *App) confirmApplyAll() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	if len(a.availableItems) == 0 {
+		a.statusBar.SetText(" [yellow]Nothing to apply[-]")
+		return
+	}
+	cat := a.categories[a.activeTabIdx]
+	a.confirm("applyAll", " Apply All ",
+		fmt.Sprintf("Apply all %d available %s?", len(a.availableItems), cat.Name),
+		a.applyAll)
+}
+
+func (a *App) confirmRemoveAll() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	items := removableItems(a.appliedItems)
+	if len(items) == 0 {
+		a.statusBar.SetText(" [yellow]Nothing to remove[-]")
+		return
+	}
+	cat := a.categories[a.activeTabIdx]
+	a.confirm("removeAll", " Remove All ",
+		fmt.Sprintf("Remove all %d applied %s?", len(items), cat.Name),
+		a.removeAll)
+}
+
+func (a *App) applyAll() {
+	a.bulkToggle(ActionApply, a.availableItems, a.linkItem, "Applied")
+}
+
+func (a *App) removeAll() {
+	a.bulkToggle(ActionRemove, removableItems(a.appliedItems), a.unlinkItem, "Removed")
+}
+
+// removableItems leaves out the stray links listed among applied items (see
+// strayLinkWarning): lazyclaude did not create them, so it never removes them.
+func removableItems(items []Item) []Item {
+	var removable []Item
+	for _, item := range items {
+		if item.Warning == "" {
+			removable = append(removable, item)
+		}
+	}
+	return removable
+}
+
+// bulkToggle runs fn over items in the active category, refreshes once, and
+// summarizes the result in the status bar.
+func (a *App) bulkToggle(kind ActionKind, items []Item, fn func(Category, Item) error, verb string) {
+	cat := a.categories[a.activeTabIdx]
+
+	var done []Item
+	var batch []historyEntry
+	var lastErr error
+	for _, item := range items {
+		if err := fn(cat, item); err != nil {
+			lastErr = err
+			continue
+		}
+		done = append(done, item)
+		batch = append(batch, historyEntry{Kind: kind, Category: cat.Name, Item: item})
+	}
+
+	if len(done) > 0 {
+		a.lastAction = &Action{Kind: kind, Category: cat, Items: done}
+		if kind == ActionApply {
+			a.noteUsage(cat, done...)
+		}
+		a.recordBatch(kind, batch)
+	}
+
+	a.refreshAll()
+	msg := fmt.Sprintf(" %s %d items", verb, len(done))
+	if skipped := len(items) - len(done); skipped > 0 {
+		msg += fmt.Sprintf(" [red](%d skipped: %s)[-]", skipped, tview.Escape(describeFSError(lastErr)))
+	}
+	a.statusBar.SetText(msg)
+}
+
+// --- Project config sync ---
+
+// projectConfigTargets returns, for every item listed in the project config
+// that exists in the global store but is not applied, its category and item.
+func (a *App) projectConfigTargets() (cats []Category, items []Item) {
+	if a.projectConfig == nil {
+		return nil, nil
+	}
+	for _, cat := range a.categories {
+		names := a.projectConfig.Applied[cat.Name]
+		if len(names) == 0 {
+			continue
+		}
+		available, _ := scanCategory(cat, a.primaryDocs)
+		for _, name := range names {
+			if item, ok := findItem(available, name); ok {
+				cats = append(cats, cat)
+				items = append(items, item)
+			}
+		}
+	}
+	return cats, items
+}
+
+func (a *App) missingFromProjectConfig() int {
+	_, items := a.projectConfigTargets()
+	return len(items)
+}
+
+// syncProjectConfig applies every item listed in the project config.
+func (a *App) syncProjectConfig() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	if a.projectConfig == nil {
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]No %s in %s[-]", projectConfigName, a.claudeDir))
+		return
+	}
+
+	cats, items := a.projectConfigTargets()
+	a.confirm("syncProjectConfig", " Sync Config ",
+		fmt.Sprintf("Apply the %d items listed in %s?", len(items), projectConfigName),
+		func() {
+			var applied int
+			var batch []historyEntry
+			var lastErr error
+			for i, item := range items {
+				if err := a.linkItem(cats[i], item); err != nil {
+					lastErr = err
+					continue
+				}
+				a.noteUsage(cats[i], item)
+				batch = append(batch, historyEntry{Kind: ActionApply, Category: cats[i].Name, Item: item})
+				applied++
+			}
+			a.recordBatch(ActionApply, batch)
+
+			a.refreshAll()
+			msg := fmt.Sprintf(" Synced %s: applied %d items", projectConfigName, applied)
+			if skipped := len(items) - applied; skipped > 0 {
+				msg += fmt.Sprintf(" [red](%d skipped: %s)[-]", skipped, tview.Escape(describeFSError(lastErr)))
+			}
+			a.statusBar.SetText(msg)
+		})
+}
+
+func (a *App) confirmWriteProjectConfig() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	a.confirm("writeProjectConfig", " Write Config ",
+		fmt.Sprintf("Write applied items to %s?", filepath.Join(a.claudeDir, projectConfigName)),
+		a.writeProjectConfigFromState)
+}
+
+// writeProjectConfigFromState captures every currently applied item into the
+// project config file.
+func (a *App) writeProjectConfigFromState() {
+	pc := &ProjectConfig{Applied: map[string][]string{}}
+	for _, cat := range a.categories {
+		_, applied := scanCategory(cat, a.primaryDocs)
+		for _, item := range applied {
+			pc.Applied[cat.Name] = append(pc.Applied[cat.Name], item.Name)
+		}
+	}
+
+	if err := writeProjectConfig(a.claudeDir, pc); err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %v", err))
+		return
+	}
+	a.projectConfig = pc
+	a.statusBar.SetText(fmt.Sprintf(" Wrote %s", filepath.Join(a.claudeDir, projectConfigName)))
+}
+
+// --- Refresh ---
+
+func (a *App) refreshAll() {
+	a.scanGen++
+	a.scanning = false
+	a.loadItems()
+	a.loadAppliedCounts()
+	a.renderAll()
+}
+
+// reload rescans the global stores for categories, then refreshes the lists.
+// The active tab is kept if its category still exists.
+func (a *App) reload() {
+	parts, err := a.rescan()
+	if err != nil {
+		a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(err.Error()))
+		return
+	}
+	if len(parts) == 0 {
+		a.statusBar.SetText(" Reloaded: no changes")
+		return
+	}
+	a.statusBar.SetText(" Reloaded: " + strings.Join(parts, ", "))
+}
+
+// rescan reloads the categories and refreshes the lists, returning what
+// changed in the active category and the category set. On error the
+// previous categories are kept.
+func (a *App) rescan() ([]string, error) {
+	active := a.categories[a.activeTabIdx].Name
+	before := a.itemSnapshot()
+	previous := a.categories
+	if err := a.loadCategories(); err != nil {
+		a.categories = previous
+		return nil, err
+	}
+	if len(a.categories) == 0 {
+		a.categories = previous
+		return nil, fmt.Errorf("no categories found in %s", strings.Join(a.globalRoots, ", "))
+	}
+
+	a.activeTabIdx = min(a.activeTabIdx, len(a.categories)-1)
+	for i, cat := range a.categories {
+		if cat.Name == active {
+			a.activeTabIdx = i
+			break
+		}
+	}
+	a.refreshAll()
+
+	var changes []string
+	if a.categories[a.activeTabIdx].Name == active {
+		changes = diffSnapshots(before, a.itemSnapshot())
+	}
+	if added, removed := diffCategories(previous, a.categories); added+removed > 0 {
+		changes = append(changes, countChange(added, "category", "categories", "added"), countChange(removed, "category", "categories", "removed"))
+	}
+	var parts []string
+	for _, change := range changes {
+		if change != "" {
+			parts = append(parts, change)
+		}
+	}
+	return parts, nil
+}
+
+// reloadConfig re-reads config.yaml and keys.yaml and applies them without
+// a restart. If config.yaml is invalid the error is shown and the current
+// settings stay in effect; a bad keys.yaml keeps the current keys.
+func (a *App) reloadConfig() {
+	cfg, err := loadConfig()
+	if os.IsNotExist(err) {
+		cfg, err = &Config{}, nil
+	}
+	if err == nil {
+		err = a.applyConfig(cfg)
+	}
+	if err != nil {
+		a.statusBar.SetText(" [red]Error:[-] config.yaml: " + tview.Escape(err.Error()))
+		return
+	}
+	overrides, keysErr := loadKeyOverrides()
+	var keyWarnings []string
+	if keysErr == nil {
+		keyWarnings = a.buildKeymap(overrides)
+	}
+
+	a.availableList.ShowSecondaryText(a.showDescs)
+	for _, list := range []*tview.List{a.availableList, a.appliedList} {
+		list.SetSelectedBackgroundColor(a.selectionColor)
+	}
+	a.previewView.SetBackgroundColor(a.previewBackground())
+	a.previewGlobal.SetBackgroundColor(a.previewBackground())
+	a.setPreviewWidth(a.previewWidth)
+	a.setAppliedSize(a.appliedSize)
+	if _, err := a.rescan(); err != nil {
+		a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(err.Error()))
+		return
+	}
+
+	switch {
+	case keysErr != nil:
+		a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(keysErr.Error()))
+	case len(keyWarnings) > 0:
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]keys.yaml: %s[-]", tview.Escape(strings.Join(keyWarnings, "; "))))
+	default:
+		a.statusBar.SetText(" Reloaded configuration")
+	}
+}
+
+// itemSnapshot records whether each item of the active category is applied,
+// keyed by its global path.
+func (a *App) itemSnapshot() map[string]bool {
+	snapshot := map[string]bool{}
+	for _, item := range a.availableItems {
+		snapshot[item.GlobalPath] = false
+	}
+	for _, item := range a.appliedItems {
+		snapshot[item.GlobalPath] = true
+	}
+	return snapshot
+}
+
+// diffSnapshots describes how the active category changed between two
+// snapshots: items added or removed, and items applied or removed outside
+// lazyclaude.
+func diffSnapshots(before, after map[string]bool) []string {
+	var added, removed, applied, unapplied int
+	for path, nowApplied := range after {
+		wasApplied, existed := before[path]
+		switch {
+		case !existed:
+			added++
+		case nowApplied && !wasApplied:
+			applied++
+		case !nowApplied && wasApplied:
+			unapplied++
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			removed++
+		}
+	}
+	return []string{
+		countChange(added, "item", "items", "added"),
+		countChange(removed, "item", "items", "removed"),
+		countChange(applied, "item", "items", "newly applied"),
+		countChange(unapplied, "item", "items", "no longer applied"),
+	}
+}
+
+// diffCategories counts categories that appeared or disappeared.
+func diffCategories(before, after []Category) (added, removed int) {
+	names := map[string]bool{}
+	for _, cat := range before {
+		names[cat.Name] = true
+	}
+	for _, cat := range after {
+		if !names[cat.Name] {
+			added++
+		}
+		delete(names, cat.Name)
+	}
+	return added, len(names)
+}
+
+// countChange formats "N things verb", or "" when n is zero.
+func countChange(n int, singular, pluralForm, verb string) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d %s %s", n, plural(n, singular, pluralForm), verb)
+}
+
+// refreshAsync rescans the active category off the main goroutine, showing a
+// scanning indicator until the results arrive. Results are discarded if
+// another refresh started in the meantime (e.g. the user switched tabs again).
+func (a *App) refreshAsync() {
+	a.scanGen++
+	gen := a.scanGen
+	activeIdx := a.activeTabIdx
+	categories := a.categories
+	cat := categories[activeIdx]
+	projectRoot := filepath.Dir(a.claudeDir)
+	docs := a.primaryDocs // applyConfig may replace it while the scan runs
+	a.pruneBrokenLinks(cat)
+
+	// Clear the stale lists so nothing from the previous tab can be toggled.
+	a.availableItems = nil
+	a.appliedItems = nil
+	a.scanning = true
+	a.renderAll()
+	a.statusBar.SetText(" [yellow]Scanning…[-]")
+
+	go func() {
+		available, applied := scanCategory(cat, docs)
+		annotateGitState(projectRoot, cat, applied)
+		counts := appliedCounts(categories, activeIdx, len(applied), docs)
+		a.app.QueueUpdateDraw(func() {
+			if gen != a.scanGen {
+				return
+			}
+			a.scanning = false
+			a.sortAvailable(cat, available)
+			a.sortItems(applied)
+			a.availableItems, a.appliedItems = available, applied
+			a.appliedCounts = counts
+			a.renderAll()
+		})
+	}()
+}
+
+// renderAll redraws every widget from the currently loaded state.
+func (a *App) renderAll() {
+	a.refreshAvailableList()
+	a.refreshAppliedList()
+	a.updateTabBar()
+	a.updatePanelTitles()
+	a.updatePreview()
+	a.updateStatusBar()
+	a.updateBorderColors()
+}
+
+func (a *App) refreshAvailableList() {
+	currentIdx := a.availableList.GetCurrentItem()
+	a.availableList.Clear()
+	a.availableRows = a.availableRows[:0]
+
+	cat := a.categories[a.activeTabIdx]
+	for _, group := range a.availableGroups() {
+		if group.label != "" {
+			a.availableRows = append(a.availableRows, -1)
+			a.availableList.AddItem("[darkgray::b]── "+tview.Escape(group.label)+" ──[-::-]", "", 0, nil)
+		}
+		for _, idx := range group.items {
+			item := a.availableItems[idx]
+			prefix := "  "
+			if a.isFavorite(cat, item) {
+				prefix = "[yellow]★[-] "
+			}
+			desc := ""
+			if a.showDescs {
+				desc = "    " + tview.Escape(a.itemDescription(item))
+			}
+			suffix := itemSuffix(item)
+			if a.isNew(item) {
+				suffix += " [darkgray::i]new[-::-]"
+			}
+			if n := a.usage.count(cat.Name, item.Name); n > 0 && a.sortOrder == SortByUsage {
+				suffix += fmt.Sprintf(" [darkgray]×%d[-]", n)
+			}
+			a.availableRows = append(a.availableRows, idx)
+			a.availableList.AddItem(prefix+a.listName(item)+suffix, desc, 0, nil)
+		}
+	}
+
+	count := a.availableList.GetItemCount()
+	if currentIdx >= count {
+		currentIdx = count - 1
+	}
+	if currentIdx >= 0 {
+		a.availableList.SetCurrentItem(currentIdx)
+		if a.isGroupHeader(a.availableList, currentIdx) {
+			if row, ok := a.stepCursor(a.availableList, currentIdx, 1, false); ok {
+				a.availableList.SetCurrentItem(row)
+			} else if row, ok := a.stepCursor(a.availableList, currentIdx, -1, false); ok {
+				a.availableList.SetCurrentItem(row)
+			}
+		}
+	}
+}
+
+// isNew reports whether item changed within the configured recency window.
+func (a *App) isNew(item Item) bool {
+	return a.newWithin > 0 && !item.ModTime.IsZero() && time.Since(item.ModTime) < a.newWithin
+}
+
+// descCacheEntry is a parsed item description and the modification time of
+// the file it was read from.
+type descCacheEntry struct {
+	desc    string
+	modTime time.Time
+}
+
+// itemDescription returns a one-line description of item, read from the
+// start of its file (or a directory's primary doc) and cached until it
+// changes.
+func (a *App) itemDescription(item Item) string {
+	path := item.GlobalPath
+	if item.IsDir {
+		if path = primaryDoc(path, a.primaryDocs); path == "" {
+			return ""
+		}
+	}
+	mod := modTime(path)
+	if entry, ok := a.descCache[path]; ok && entry.modTime.Equal(mod) {
+		return entry.desc
+	}
+
+	desc := ""
+	if f, err := os.Open(path); err == nil {
+		buf := make([]byte, 1024)
+		n, _ := io.ReadFull(f, buf)
+		f.Close()
+		desc = parseDescription(string(trimPartialRune(buf[:n])))
+	}
+	if a.descCache == nil {
+		a.descCache = map[string]descCacheEntry{}
+	}
+	a.descCache[path] = descCacheEntry{desc: desc, modTime: mod}
+	return desc
+}
+
+// parseDescription extracts a description from the head of a markdown file:
+// the frontmatter "description" field, else the first "> quote" or paragraph
+// line after the title.
+func parseDescription(head string) string {
+	lines := strings.Split(strings.ReplaceAll(head, "\r\n", "\n"), "\n")
+	if len(lines) > 0 && lines[0] == "---" {
+		for i := 1; i < len(lines) && lines[i] != "---"; i++ {
+			if value, ok := strings.CutPrefix(lines[i], "description:"); ok {
+				value = strings.TrimSpace(value)
+				if strings.HasPrefix(value, ">") || strings.HasPrefix(value, "|") {
+					// Block scalar: the text starts on the next line.
+					if i+1 < len(lines) {
+						value = strings.TrimSpace(lines[i+1])
+					}
+				}
+				return strings.Trim(value, `"'`)
+			}
+		}
+		for i := 1; i < len(lines); i++ {
+			if lines[i] == "---" {
+				lines = lines[i+1:]
+				break
+			}
+		}
+	}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+		return strings.TrimSpace(strings.TrimPrefix(line, ">"))
+	}
+	return ""
+}
+
+// toggleDescriptions shows or hides the description line under available items.
+func (a *App) toggleDescriptions() {
+	a.showDescs = !a.showDescs
+	a.availableList.ShowSecondaryText(a.showDescs)
+	a.refreshAvailableList()
+}
+
+// listName is how item is labelled in the lists: its display name, or with
+// show_paths its path within the category.
+func (a *App) listName(item Item) string {
+	if a.showPaths && item.DisplayPath != "" {
+		return item.DisplayPath
+	}
+	return item.DisplayName()
+}
+
+// togglePaths switches the lists between item names and paths.
+func (a *App) togglePaths() {
+	a.showPaths = !a.showPaths
+	a.refreshAvailableList()
+	a.refreshAppliedList()
+}
+
+// groupMode selects how the Available list is divided into sections.
+type groupMode int
+
+const (
+	groupNone   groupMode = iota
+	groupLetter           // by first letter of the name
+	groupType             // directories, then files
+)
+
+// itemGroup is one section of the Available list.
+type itemGroup struct {
+	label string // empty when the list is not grouped
+	items []int  // indexes into availableItems, in list order
+}
+
+// availableGroups splits availableItems into sections for the current group
+// mode. Items keep their relative order within a section.
+func (a *App) availableGroups() []itemGroup {
+	if a.groupMode == groupNone {
+		all := make([]int, len(a.availableItems))
+		for i := range all {
+			all[i] = i
+		}
+		return []itemGroup{{items: all}}
+	}
+
+	byLabel := map[string][]int{}
+	var labels []string
+	for i, item := range a.availableItems {
+		label := a.groupLabel(item)
+		if _, ok := byLabel[label]; !ok {
+			labels = append(labels, label)
+		}
+		byLabel[label] = append(byLabel[label], i)
+	}
+	if a.groupMode == groupLetter {
+		sort.Slice(labels, func(i, j int) bool { return (labels[i] < labels[j]) == a.ascending })
+	} else {
+		sort.Slice(labels, func(i, j int) bool { return labels[i] == "Directories" && labels[j] != "Directories" })
+	}
+
+	groups := make([]itemGroup, len(labels))
+	for i, label := range labels {
+		groups[i] = itemGroup{label: label, items: byLabel[label]}
+	}
+	return groups
+}
+
+// groupLabel returns the section an item belongs to in the current group mode.
+func (a *App) groupLabel(item Item) string {
+	if a.groupMode == groupType {
+		if item.IsDir {
+			return "Directories"
+		}
+		return "Files"
+	}
+	r, _ := utf8.DecodeRuneInString(item.Name)
+	if !unicode.IsLetter(r) {
+		return "#"
+	}
+	return string(unicode.ToUpper(r))
+}
+
+// cycleGroupMode switches the Available list between flat, by-letter, and
+// by-type views.
+func (a *App) cycleGroupMode() {
+	a.groupMode = (a.groupMode + 1) % 3
+	a.refreshAvailableList()
+	a.updatePreview()
+	labels := map[groupMode]string{groupNone: "off", groupLetter: "by first letter", groupType: "by type"}
+	a.statusBar.SetText(fmt.Sprintf(" Grouping: [green]%s[-]", labels[a.groupMode]))
+}
+
+// isGroupHeader reports whether row is a non-selectable section header.
+func (a *App) isGroupHeader(list *tview.List, row int) bool {
+	return list == a.availableList && row >= 0 && row < len(a.availableRows) && a.availableRows[row] < 0
+}
+
+// availableIndex returns the availableItems index under the Available
+// cursor, or -1 if the list is empty.
+func (a *App) availableIndex() int {
+	row := a.availableList.GetCurrentItem()
+	if row < 0 || row >= len(a.availableRows) {
+		return -1
+	}
+	return a.availableRows[row]
+}
+
+func (a *App) refreshAppliedList() {
+	currentIdx := a.appliedList.GetCurrentItem()
+	a.appliedList.Clear()
+
+	for _, item := range a.appliedItems {
+		prefix := "[green]+[-] "
+		displayName := a.listName(item)
+		if item.Warning != "" {
+			prefix = "[yellow]![-] "
+			displayName = "[yellow]" + displayName + "[-]"
+		}
+		if item.LinkName != "" {
+			displayName += fmt.Sprintf(" [darkgray]as %s[-]", tview.Escape(item.LinkName))
+		}
+		if item.GitState != "" {
+			displayName += fmt.Sprintf(" [darkgray](%s)[-]", item.GitState)
+		}
+		a.appliedList.AddItem(prefix+displayName+itemSuffix(item), "", 0, nil)
+	}
+
+	if currentIdx >= len(a.appliedItems) {
+		currentIdx = len(a.appliedItems) - 1
+	}
+	if currentIdx >= 0 {
+		a.appliedList.SetCurrentItem(currentIdx)
+	}
+}
+
+// itemSuffix marks an item whose global entry is a symlink, and labels it
+// with its store when several are configured.
+func itemSuffix(item Item) string {
+	suffix := ""
+	if item.IsLink {
+		suffix = " [darkgray]↪[-]"
+	}
+	if item.Origin != "" {
+		suffix += fmt.Sprintf(" [darkgray](%s)[-]", tview.Escape(item.Origin))
+	}
+	return suffix
+}
+
+func (a *App) updateTabBar() {
+	var parts []string
+	for i, cat := range a.categories {
+		name := tview.Escape(a.theme.label(cat.Name))
+		if g := a.theme.glyph(cat.Name); g != "" {
+			name = g + " " + name
+		}
+		if linkedCategory(cat) {
+			name += " (linked)"
+		} else if i < len(a.appliedCounts) && a.appliedCounts[i] > 0 {
+			name = fmt.Sprintf("%s (%d)", name, a.appliedCounts[i])
+		}
+		if i == a.activeTabIdx {
+			parts = append(parts, fmt.Sprintf("[green::b] %s [-:-:-]", name))
+		} else {
+			parts = append(parts, fmt.Sprintf("[darkgray] %s [-]", name))
+		}
+	}
+	text := strings.Join(parts, "│")
+	if a.readOnly {
+		text = "[red::b] READ-ONLY [-:-:-]│" + text
+	}
+	a.tabBar.SetText(text)
+}
+
+func (a *App) updatePanelTitles() {
+	catName := tview.Escape(a.theme.label(a.categories[a.activeTabIdx].Name))
+	arrow := "▲"
+	if !a.ascending {
+		arrow = "▼"
+	}
+	order := arrow
+	if a.sortOrder == SortByUsage {
+		order = "by use " + arrow
+	}
+	a.availableList.SetTitle(fmt.Sprintf(" [1] Available %s (%d) %s ", catName, len(a.availableItems), order))
+	if linkedCategory(a.categories[a.activeTabIdx]) {
+		a.appliedList.SetTitle(fmt.Sprintf(" [2] Applied %s (linked as directory) ", catName))
+		return
+	}
+	a.appliedList.SetTitle(fmt.Sprintf(" [2] Applied %s (%d) %s ", catName, len(a.appliedItems), arrow))
+}
+
+func (a *App) updateStatusBar() {
+	a.statusBar.SetText(tview.Escape(a.statusHint()))
+}
+
+// statusHint returns the key hints for whatever has focus: an open modal,
+// the preview column, or the focused list.
+func (a *App) statusHint() string {
+	switch {
+	case a.zoomOpen:
+		return " [J/K] scroll  [/ n/N] search  [#] line numbers  [esc/q/f] close"
+	case a.treeOpen:
+		return " [j/k] navigate  [enter] fold/unfold or open file  [+/-] depth  [J/K] scroll preview  [esc/q] close"
+	case a.outlineOpen:
+		return " [j/k] navigate  [enter] fold/unfold  [esc/q] close"
+	case a.helpOpen:
+		return " [j/k] scroll  [esc/q] close"
+	case a.previewFocused:
+		return " [j/k] scroll  [d/u] half page  [g/G] top/bottom  [/ n/N] search  " + a.keyHints(
+			keyHint{"full preview", []string{"zoomPreview"}},
+			keyHint{"back to the lists", []string{"focusAvailable", "focusApplied"}},
+			keyHint{"help", []string{"help"}},
+			keyHint{"quit", []string{"quit"}})
+	}
+
+	panels := keyHint{"panels", []string{"focusAvailable", "focusApplied"}}
+	navigate := keyHint{"navigate", []string{"cursorDown", "cursorUp"}}
+	tabs := keyHint{"tabs", []string{"prevTab", "nextTab"}}
+	help := keyHint{"help", []string{"help"}}
+	quit := keyHint{"quit", []string{"quit"}}
+	applied := a.currentPanelIdx == 1
+	if a.compact {
+		preview := keyHint{"preview", []string{"showPreview"}}
+		if applied {
+			return " " + a.keyHints(panels, navigate, preview, keyHint{"remove", []string{"toggleSelected"}}, tabs, help, quit)
+		}
+		return " " + a.keyHints(panels, navigate, preview, keyHint{"apply", []string{"toggleSelected"}}, tabs, help, quit)
+	}
+	scroll := keyHint{"scroll preview", []string{"scrollPreviewDown", "scrollPreviewUp"}}
+	zoom := keyHint{"full preview", []string{"zoomPreview"}}
+	if applied {
+		return " " + a.keyHints(panels, navigate,
+			keyHint{"remove", []string{"toggleSelected", "moveToAvailable"}},
+			keyHint{"remove all", []string{"removeAll"}},
+			keyHint{"undo", []string{"undo"}},
+			keyHint{"split", []string{"splitPreview"}},
+			keyHint{"save config", []string{"writeProjectConfig"}},
+			scroll, zoom, tabs, help, quit)
+	}
+	return " " + a.keyHints(panels, navigate,
+		keyHint{"apply", []string{"toggleSelected", "moveToApplied"}},
+		keyHint{"apply as", []string{"applyAs"}},
+		keyHint{"apply all", []string{"applyAll"}},
+		keyHint{"star", []string{"toggleFavorite"}},
+		keyHint{"sync config", []string{"syncProjectConfig"}},
+		scroll, zoom,
+		keyHint{"tree", []string{"showTree"}},
+		tabs, help, quit)
+}
+
+// keyHint is one "[keys] label" entry of the status bar hint: the keys
+// bound to actions, in order, followed by label.
+type keyHint struct {
+	label   string
+	actions []string
+}
+
+// keyHints renders hints from the active keymap, so rebound keys show up
+// and unbound actions are left out.
+func (a *App) keyHints(hints ...keyHint) string {
+	bound := a.boundKeys()
+	var entries []string
+	for _, hint := range hints {
+		var keys []string
+		for _, action := range hint.actions {
+			for _, key := range bound[action] {
+				keys = append(keys, hintKeyName(key))
+			}
+		}
+		if len(keys) > 0 {
+			entries = append(entries, "["+strings.Join(keys, "/")+"] "+hint.label)
+		}
+	}
+	return strings.Join(entries, "  ")
+}
+
+// hintKeyName shortens a keys.yaml key name for the status bar hint.
+func hintKeyName(name string) string {
+	switch name {
+	case "Left":
+		return "←"
+	case "Right":
+		return "→"
+	}
+	if utf8.RuneCountInString(name) > 1 {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// pressKey tells the user how to run action: "press <key>", or through the
+// command palette when no key is bound to it.
+func (a *App) pressKey(action string) string {
+	if key := a.keyFor(action); key != "" {
+		return "press " + tview.Escape(key)
+	}
+	return "run \"" + actionTitles[action] + "\" from the command palette"
+}
+
+// keyFor returns a key bound to action for use in a sentence, preferring a
+// single character, or "" when the action is unbound.
+func (a *App) keyFor(action string) string {
+	keys := a.boundKeys()[action]
+	for _, key := range keys {
+		if utf8.RuneCountInString(key) == 1 {
+			return key
+		}
+	}
+	if len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
+
+// updateInfoBar shows where the selected applied item's project entry
+// points, and hides the line when the Applied panel is not focused.
+func (a *App) updateInfoBar() {
+	item := a.selectedItem()
+	if a.currentPanelIdx != 1 || item == nil {
+		a.infoBar.Clear()
+		a.rootFlex.ResizeItem(a.infoBar, 0, 0)
+		return
+	}
+	a.rootFlex.ResizeItem(a.infoBar, 1, 0)
+
+	projectPath := filepath.Join(a.categories[a.activeTabIdx].ProjectDir, item.linkName())
 	info, err := os.Lstat(projectPath)
+	switch {
+	case err != nil:
+		a.infoBar.SetText(fmt.Sprintf(" [red]missing:[-] %s", tview.Escape(projectPath)))
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(projectPath)
+		if err != nil {
+			a.infoBar.SetText(fmt.Sprintf(" [red]Error:[-] %v", err))
+			return
+		}
+		line := " [darkgray]symlink →[-] " + tview.Escape(target)
+		if _, err := os.Stat(projectPath); err != nil {
+			line += "  [red](broken)[-]"
+		}
+		a.infoBar.SetText(line)
+	default:
+		kind := "copy"
+		switch strategy := a.categories[a.activeTabIdx].Strategy; {
+		case info.IsDir() && strategy == StrategyMerge:
+			kind = "merged directory"
+		case strategy == StrategyHardlink:
+			kind = "hardlink"
+		}
+		line := fmt.Sprintf(" [darkgray]%s:[-] %s", kind, tview.Escape(projectPath))
+		if !info.IsDir() && !a.splitPreview && differsFromGlobal(projectPath, item.GlobalPath) {
+			line += "  [yellow]differs from global — v to compare[-]"
+		}
+		a.infoBar.SetText(line)
+	}
+}
+
+// --- Preview ---
+
+func (a *App) updatePreview() {
+	a.updateInfoBar()
+	a.rememberScroll()
+	a.previewView.Clear()
+	a.previewContent = ""
+	a.previewPathKey = ""
+	a.clearSearch()
+	if a.previewHidden && !a.compact {
+		return
+	}
+
+	item := a.selectedItem()
+	if item == nil {
+		a.previewView.SetText(a.emptyStateText())
+		a.updateSplitPreview(nil, "")
+		return
+	}
+
+	path := a.previewPath(item)
+	if item.IsDir {
+		a.showDirectoryPreview(item, path)
+	} else {
+		a.showFilePreview(item, path)
+	}
+	a.previewPathKey = path
+	a.restoreScroll()
+	a.updateSplitPreview(item, path)
+}
+
+// updateSplitPreview fills the global column of the split preview, shown for
+// applied items while the split is toggled on.
+func (a *App) updateSplitPreview(item *Item, path string) {
+	if !a.splitPreview || a.currentPanelIdx != 1 || item == nil {
+		a.previewFlex.ResizeItem(a.previewGlobal, 0, 0)
+		a.previewView.SetTitle(" Preview ")
+		return
+	}
+	a.previewFlex.ResizeItem(a.previewGlobal, 0, 1)
+	a.previewView.SetTitle(" Project ")
+
+	source := item.GlobalPath
+	if item.IsDir {
+		if source = primaryDoc(source, a.primaryDocs); source == "" {
+			var b strings.Builder
+			fmt.Fprintf(&b, "[cyan::b]%s/[-:-:-]\n\n", item.Name)
+			a.buildTree(&b, a.newTreeIgnore(item.GlobalPath), item.GlobalPath, "", 0, defaultTreeDepth)
+			a.previewGlobal.SetText(b.String()).ScrollToBeginning()
+			return
+		}
+		path = filepath.Join(path, filepath.Base(source))
+	}
+	content, err := a.readPreviewFile(source)
+	if err != nil {
+		a.previewGlobal.SetText(fmt.Sprintf("[red]Error reading file:[-] %v", err))
+		return
+	}
+	header := fmt.Sprintf("[cyan::b]%s[-:-:-]", tview.Escape(source))
+	if canonicalPath(source) == canonicalPath(path) {
+		header += "\n[darkgray]same file as the project version (symlink)[-]"
+	}
+	a.previewGlobal.SetText(header + "\n\n" + a.highlightCode(content, detectLanguage(source))).ScrollToBeginning()
+}
+
+// toggleSplitPreview shows or hides the global version of applied items
+// beside the project version.
+func (a *App) toggleSplitPreview() {
+	a.splitPreview = !a.splitPreview
+	a.updatePreview()
+	if a.splitPreview && a.currentPanelIdx != 1 {
+		a.statusBar.SetText(" [darkgray]Split preview on — it shows for items in the Applied panel[-]")
+	}
+}
+
+// differsFromGlobal reports whether a copied project file no longer matches
+// its global source.
+func differsFromGlobal(projectPath, globalPath string) bool {
+	project, err := os.ReadFile(projectPath)
+	if err != nil {
+		return false
+	}
+	global, err := os.ReadFile(globalPath)
+	return err == nil && string(project) != string(global)
+}
+
+// emptyStateText explains why there is nothing to preview: the focused list
+// is empty, the category has no items, or its directory is missing.
+func (a *App) emptyStateText() string {
+	if a.scanning {
+		return "[darkgray]Scanning…[-]"
+	}
+	cat := a.categories[a.activeTabIdx]
+	dirs := make([]string, len(cat.GlobalDirs))
+	for i, dir := range cat.GlobalDirs {
+		dirs[i] = tview.Escape(dir.Path)
+	}
+	where := strings.Join(dirs, " or ")
+
+	missing := true
+	for _, dir := range cat.GlobalDirs {
+		if _, err := os.Stat(dir.Path); err == nil {
+			missing = false
+		}
+	}
+
+	switch {
+	case linkedCategory(cat):
+		return fmt.Sprintf("[green::b]%s is linked as a directory[-:-:-]\n\n%s → %s\n\n[darkgray]Every item is applied through the link. To unlink it, %s.[-]",
+			cat.Name, tview.Escape(cat.ProjectDir), where, a.pressKey("linkCategory"))
+	case missing:
+		return fmt.Sprintf("[yellow]%s does not exist[-]\n\n[darkgray]Create it, or %s to rescan categories.[-]", where, a.pressKey("reload"))
+	case len(a.availableItems) == 0 && len(a.appliedItems) == 0:
+		msg := fmt.Sprintf("[yellow]No items in %s[-]\n\n[darkgray]Add files or directories to %s, then %s.[-]", cat.Name, where, a.pressKey("reload"))
+		if a.storesEmpty() {
+			msg += fmt.Sprintf("\n\n[darkgray]Every category is empty — the global store lives in %s.[-]", tview.Escape(strings.Join(a.globalRoots, ", ")))
+		}
+		return msg
+	case a.currentPanelIdx == 0:
+		return fmt.Sprintf("[darkgray]Every %s item is applied.[-]", cat.Name)
+	default:
+		return fmt.Sprintf("[darkgray]Nothing from %s is applied yet — select an item in Available and %s.[-]", cat.Name, a.pressKey("toggleSelected"))
+	}
+}
+
+// storesEmpty reports whether no category in any store has an item.
+func (a *App) storesEmpty() bool {
+	for _, cat := range a.categories {
+		for _, dir := range cat.GlobalDirs {
+			if len(visibleEntries(dir.Path)) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// scrollPosition is a remembered preview offset and the modification time of
+// the file it was recorded for.
+type scrollPosition struct {
+	row, col int
+	modTime  time.Time
+}
+
+// rememberScroll records the scroll offset of the item being previewed.
+func (a *App) rememberScroll() {
+	if a.previewPathKey == "" {
+		return
+	}
+	if a.scrollMemory == nil {
+		a.scrollMemory = map[string]scrollPosition{}
+	}
+	row, col := a.previewView.GetScrollOffset()
+	a.scrollMemory[a.previewPathKey] = scrollPosition{row, col, modTime(a.previewPathKey)}
+}
+
+// restoreScroll scrolls the preview back to where it was last left, unless
+// the file changed since then.
+func (a *App) restoreScroll() {
+	pos, ok := a.scrollMemory[a.previewPathKey]
+	if !ok {
+		a.previewView.ScrollToBeginning()
+		return
+	}
+	if !pos.modTime.Equal(modTime(a.previewPathKey)) {
+		delete(a.scrollMemory, a.previewPathKey)
+		a.previewView.ScrollToBeginning()
+		return
+	}
+	a.previewView.ScrollTo(pos.row, pos.col)
+}
+
+// modTime returns the modification time of path, or the zero time on error.
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// previewPath returns the file to preview for item. Applied items are read
+// from the project side, so copies and locally edited files show what the
+// project actually contains.
+func (a *App) previewPath(item *Item) string {
+	if a.currentPanelIdx == 1 {
+		projectPath := filepath.Join(a.categories[a.activeTabIdx].ProjectDir, item.linkName())
+		if _, err := os.Lstat(projectPath); err == nil {
+			return projectPath
+		}
+	}
+	return item.GlobalPath
+}
+
+// readPreviewFile reads path for previewing, truncated to preview_max_bytes.
+func (a *App) readPreviewFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if len(data) <= a.previewMax {
+		return string(data), nil
+	}
+	return fmt.Sprintf("%s\n\n--- truncated at %s (preview_max_bytes) ---", trimPartialRune(data[:a.previewMax]), formatSize(a.previewMax)), nil
+}
+
+// trimPartialRune drops a multibyte character left incomplete at the end of
+// data by cutting it short. Bytes that aren't valid UTF-8 anyway are kept.
+func trimPartialRune(data []byte) []byte {
+	if r, size := utf8.DecodeLastRune(data); r != utf8.RuneError || size != 1 {
+		return data
+	}
+	// The cut fell inside a character: drop its leading bytes.
+	for back := 1; back < utf8.UTFMax && back <= len(data); back++ {
+		if tail := data[len(data)-back:]; utf8.RuneStart(tail[0]) {
+			if !utf8.FullRune(tail) {
+				return data[:len(data)-back]
+			}
+			break
+		}
+	}
+	return data
+}
+
+// formatSize renders a byte count as B, KB or MB.
+func formatSize(n int) string {
+	switch {
+	case n >= 1024*1024 && n%(1024*1024) == 0:
+		return fmt.Sprintf("%dMB", n/(1024*1024))
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1024*1024))
+	case n >= 1024 && n%1024 == 0:
+		return fmt.Sprintf("%dKB", n/1024)
+	case n >= 1024:
+		return fmt.Sprintf("%.1fKB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+func (a *App) showFilePreview(item *Item, path string) {
+	content, err := a.readPreviewFile(path)
 	if err != nil {
-		return false
+		a.previewView.SetText(fmt.Sprintf("[red]Error reading file:[-] %v", err))
+		return
 	}
-	if info.Mode()&os.ModeSymlink == 0 {
-		return false
+
+	a.previewHeader = fmt.Sprintf("[cyan::b]%s[-:-:-]%s", item.Name, headerNotes(item))
+	a.previewLang = detectLanguage(item.Name)
+	a.previewContent = content
+	if a.previewLang == "markdown" {
+		a.previewContent = a.extractFrontmatter(content, false)
 	}
-	target, err := os.Readlink(projectPath)
+	a.renderPreview()
+}
+
+// extractFrontmatter moves leading YAML frontmatter out of markdown content
+// into a key/value block appended to the preview header, and returns the
+// remaining body. With summarize, the fields in capabilityFields are pulled
+// out first into a capabilities summary. Content without valid frontmatter
+// is returned unchanged.
+func (a *App) extractFrontmatter(content string, summarize bool) string {
+	meta, body, ok := splitFrontmatter(content)
+	if !ok {
+		return content
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(meta), &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return content
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	mapping := doc.Content[0]
+	summarized := map[string]bool{}
+	if summarize {
+		b.WriteString(capabilitySummary(mapping, summarized))
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if summarized[key.Value] {
+			continue
+		}
+		fmt.Fprintf(&b, "\n[yellow]%s:[-] %s", tview.Escape(key.Value), tview.Escape(frontmatterValue(value)))
+	}
+	a.previewHeader += b.String()
+	return strings.TrimLeft(body, "\n")
+}
+
+// capabilityFields are the frontmatter keys summarized at the top of a
+// directory item's preview, under a label, in display order. The first key
+// present for a label wins.
+var capabilityFields = []struct {
+	label string
+	keys  []string
+}{
+	{"Tools", []string{"allowed-tools", "allowed_tools", "tools"}},
+	{"Triggers", []string{"when_to_use", "when-to-use", "triggers", "trigger"}},
+	{"About", []string{"description"}},
+}
+
+// capabilitySummary renders the capabilityFields found in a frontmatter
+// mapping and records their keys in used. It returns "" if none are set.
+func capabilitySummary(mapping *yaml.Node, used map[string]bool) string {
+	values := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		values[mapping.Content[i].Value] = mapping.Content[i+1]
+	}
+
+	var b strings.Builder
+	for _, field := range capabilityFields {
+		for _, key := range field.keys {
+			value, ok := values[key]
+			if !ok {
+				continue
+			}
+			used[key] = true
+			fmt.Fprintf(&b, "\n  [green]%-9s[-] %s", field.label+":", tview.Escape(frontmatterValue(value)))
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "\n[green::b]Capabilities[-:-:-]" + b.String()
+}
+
+// splitFrontmatter splits "---\n<meta>\n---\n<body>" into meta and body.
+func splitFrontmatter(content string) (meta, body string, ok bool) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(content, "---\n") {
+		return "", content, false
+	}
+	rest := content[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return "", content, false
+	}
+	after := rest[end+len("\n---"):]
+	if after != "" && after[0] != '\n' {
+		return "", content, false
+	}
+	return rest[:end], after, true
+}
+
+// frontmatterValue renders a frontmatter value on a single line: scalars
+// as-is, lists comma-separated, and anything else as inline YAML.
+func frontmatterValue(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return strings.TrimSpace(node.Value)
+	case yaml.SequenceNode:
+		var parts []string
+		for _, child := range node.Content {
+			parts = append(parts, frontmatterValue(child))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		node.Style = yaml.FlowStyle
+		out, err := yaml.Marshal(node)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+}
+
+func (a *App) showDirectoryPreview(item *Item, path string) {
+	if doc := primaryDoc(path, a.primaryDocs); doc != "" {
+		if content, err := a.readPreviewFile(doc); err == nil {
+			name := filepath.Base(doc)
+			a.previewHeader = fmt.Sprintf("[cyan::b]%s/[-:-:-] [darkgray](%s)[-]%s", item.Name, tview.Escape(name), headerNotes(item))
+			a.previewLang = detectLanguage(name)
+			a.previewContent = content
+			if a.previewLang == "markdown" {
+				a.previewContent = a.extractFrontmatter(content, true)
+			}
+			a.renderPreview()
+			return
+		}
+	}
+
+	// Fallback: directory listing
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("[cyan::b]%s/[-:-:-]%s\n\n", item.Name, headerNotes(item)))
+	a.buildTree(&b, a.newTreeIgnore(path), path, "", 0, defaultTreeDepth)
+	a.previewView.SetText(b.String())
+}
+
+// headerNotes returns the lines shown under a preview title: where a linked
+// global entry really lives, and any warning.
+func headerNotes(item *Item) string {
+	notes := ""
+	if item.IsLink {
+		notes = fmt.Sprintf("\n[darkgray]↪ links to %s[-]", tview.Escape(item.RealPath))
+	}
+	return notes + warningLine(item)
+}
+
+// warningLine renders an item's integrity warning for a preview header.
+func warningLine(item *Item) string {
+	if item.Warning == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n[yellow]⚠ %s[-]", tview.Escape(item.Warning))
+}
+
+// renderPreview highlights the current preview content, marking matches of
+// the active search query as regions.
+func (a *App) renderPreview() {
+	highlighted, matches, err := a.highlightCodeSearch(a.previewContent, a.previewLang, a.searchRegexp())
+	a.searchMatches = matches
+	header := a.previewHeader
 	if err != nil {
-		return false
+		// The note goes on the title line, above any link or warning notes.
+		title, notes, found := strings.Cut(header, "\n")
+		header = title + "  [darkgray](highlighting unavailable)[-]"
+		if found {
+			header += "\n" + notes
+		}
 	}
-	// Resolve relative symlinks
-	if !filepath.IsAbs(target) {
-		target = filepath.Join(filepath.Dir(projectPath), target)
+	a.previewView.SetText(fmt.Sprintf("%s\n\n%s", header, highlighted))
+	if a.zoomOpen {
+		if a.zoomLineNumbers {
+			highlighted = withLineNumbers(highlighted)
+		}
+		a.zoomView.SetText(fmt.Sprintf("%s\n\n%s", header, highlighted))
+	}
+}
+
+// Tree depth limits: previews use defaultTreeDepth; the tree modal can be
+// adjusted between 0 and maxTreeDepth.
+const (
+	defaultTreeDepth = 3
+	maxTreeDepth     = 10
+)
+
+func (a *App) buildTree(b *strings.Builder, ig *treeIgnore, dir, prefix string, depth, maxDepth int) {
+	if depth > maxDepth {
+		b.WriteString(prefix + "[darkgray]...[-]\n")
+		return
+	}
+
+	entries := ig.entries(dir)
+	for i, entry := range entries {
+		isLast := i == len(entries)-1
+		connector := "├── "
+		childPrefix := prefix + "│   "
+		if isLast {
+			connector = "└── "
+			childPrefix = prefix + "    "
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if target, isDir, ok := symlinkTarget(path); ok {
+			// Linked directories are not followed, so cyclic links can't loop.
+			name := entry.Name()
+			if isDir {
+				name = "[cyan]" + name + "/[-]"
+			}
+			b.WriteString(fmt.Sprintf("%s%s%s%s\n", prefix, connector, name, linkSuffix(target)))
+			continue
+		}
+		if entry.IsDir() {
+			b.WriteString(fmt.Sprintf("%s%s[cyan]%s/[-]%s\n", prefix, connector, entry.Name(), a.dirCountLabel(ig, path)))
+			a.buildTree(b, ig, path, childPrefix, depth+1, maxDepth)
+		} else {
+			b.WriteString(fmt.Sprintf("%s%s%s\n", prefix, connector, entry.Name()))
+		}
+	}
+}
+
+// symlinkTarget reports whether path is a symlink, where it points, and
+// whether it resolves to a directory.
+func symlinkTarget(path string) (target string, isDir, ok bool) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false, false
 	}
-	absTarget, err := filepath.Abs(target)
+	target, err = os.Readlink(path)
 	if err != nil {
-		return false
+		target = "?"
 	}
-	absGlobal, err := filepath.Abs(globalPath)
+	if info, err := os.Stat(path); err == nil {
+		isDir = info.IsDir()
+	}
+	return target, isDir, true
+}
+
+// linkSuffix renders the " → target" note after a symlinked tree entry.
+func linkSuffix(target string) string {
+	return " [magenta]→ " + tview.Escape(target) + "[-]"
+}
+
+// visibleEntries lists dir without hidden (dot) entries.
+func visibleEntries(dir string) []os.DirEntry {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return false
+		return nil
 	}
-	if absTarget != absGlobal {
+	visible := entries[:0]
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), ".") {
+			visible = append(visible, entry)
+		}
+	}
+	return visible
+}
+
+// defaultTreeIgnore are the patterns hidden from trees when tree_ignore is
+// not configured. Dot entries such as .git are always hidden.
+var defaultTreeIgnore = []string{"node_modules", "__pycache__"}
+
+// treeIgnore hides entries under a directory item's root that match
+// gitignore-style patterns. A nil *treeIgnore hides only dot entries.
+type treeIgnore struct {
+	root     string
+	patterns []ignorePattern
+}
+
+// ignorePattern is one parsed gitignore line.
+type ignorePattern struct {
+	glob     string
+	negate   bool // "!pattern" re-includes what earlier patterns ignored
+	dirOnly  bool // "pattern/" matches directories only
+	anchored bool // the pattern contains a slash, so it matches the path from the root
+}
+
+// newTreeIgnore builds the filter for a tree rooted at root from tree_ignore
+// and, with tree_gitignore, the .gitignore in root.
+func (a *App) newTreeIgnore(root string) *treeIgnore {
+	lines := a.treeIgnore
+	if a.gitignore {
+		if data, err := os.ReadFile(filepath.Join(root, ".gitignore")); err == nil {
+			lines = append(slices.Clone(lines), strings.Split(string(data), "\n")...)
+		}
+	}
+	t := &treeIgnore{root: root}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var p ignorePattern
+		if p.negate = strings.HasPrefix(line, "!"); p.negate {
+			line = line[1:]
+		}
+		if p.dirOnly = strings.HasSuffix(line, "/"); p.dirOnly {
+			line = strings.TrimSuffix(line, "/")
+		}
+		p.anchored = strings.Contains(line, "/")
+		p.glob = strings.TrimPrefix(line, "/")
+		t.patterns = append(t.patterns, p)
+	}
+	return t
+}
+
+// ignored reports whether path should be hidden. The last matching pattern
+// decides, as in git.
+func (t *treeIgnore) ignored(path string, isDir bool) bool {
+	if t == nil {
 		return false
 	}
-	// Validate the target still exists
-	if _, err := os.Stat(projectPath); err != nil {
-		// Broken symlink — clean it up
-		os.Remove(projectPath)
+	rel, err := filepath.Rel(t.root, path)
+	if err != nil {
 		return false
 	}
-	return true
+	ignored := false
+	for _, p := range t.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		subject := filepath.Base(path)
+		if p.anchored {
+			subject = filepath.ToSlash(rel)
+		}
+		if ok, _ := filepath.Match(p.glob, subject); ok {
+			ignored = !p.negate
+		}
+	}
+	return ignored
 }
 
-func (a *App) setupUI() {
-	a.app = tview.NewApplication()
-	selectionColor := tcell.NewRGBColor(106, 159, 181)
-
-	// Tab bar
-	a.tabBar = tview.NewTextView().
-		SetDynamicColors(true).
-		SetTextAlign(tview.AlignLeft)
+// entries lists dir without dot entries and ignored entries.
+func (t *treeIgnore) entries(dir string) []os.DirEntry {
+	visible := visibleEntries(dir)
+	if t == nil {
+		return visible
+	}
+	kept := visible[:0]
+	for _, entry := range visible {
+		if !t.ignored(filepath.Join(dir, entry.Name()), entry.IsDir()) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
 
-	// Available list
-	a.availableList = tview.NewList().
-		ShowSecondaryText(false).
-		SetHighlightFullLine(true).
-		SetSelectedBackgroundColor(selectionColor).
-		SetSelectedTextColor(tcell.ColorWhite)
-	a.availableList.SetBorder(true).
-		SetTitleAlign(tview.AlignLeft).
-		SetBorderColor(tcell.ColorDefault)
+// dirCountLabel returns a dim " (N entries)" or " (N files)" suffix for a
+// directory node, according to the tree_count setting.
+func (a *App) dirCountLabel(ig *treeIgnore, dir string) string {
+	switch a.treeCount {
+	case TreeCountNone:
+		return ""
+	case TreeCountFiles:
+		n := countFiles(ig, dir)
+		return fmt.Sprintf(" [darkgray](%d %s)[-]", n, plural(n, "file", "files"))
+	default:
+		n := len(ig.entries(dir))
+		return fmt.Sprintf(" [darkgray](%d %s)[-]", n, plural(n, "entry", "entries"))
+	}
+}
 
-	a.availableList.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
-		if a.currentPanelIdx == 0 {
-			a.updatePreview()
+// countFiles counts the non-hidden, non-ignored files under dir at any depth.
+func countFiles(ig *treeIgnore, dir string) int {
+	count := 0
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if path != dir && (strings.HasPrefix(d.Name(), ".") || ig.ignored(path, d.IsDir())) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
 		}
+		if !d.IsDir() {
+			count++
+		}
+		return nil
 	})
+	return count
+}
 
-	// Applied list
-	a.appliedList = tview.NewList().
-		ShowSecondaryText(false).
-		SetHighlightFullLine(true).
-		SetSelectedBackgroundColor(selectionColor).
-		SetSelectedTextColor(tcell.ColorWhite)
-	a.appliedList.SetBorder(true).
-		SetTitleAlign(tview.AlignLeft).
-		SetBorderColor(tcell.ColorDefault)
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
 
-	a.appliedList.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
-		if a.currentPanelIdx == 1 {
-			a.updatePreview()
+// --- Clipboard ---
+
+// selectedItem returns the item under the cursor in the focused list.
+func (a *App) selectedItem() *Item {
+	switch a.currentPanelIdx {
+	case 0:
+		if idx := a.availableIndex(); idx >= 0 {
+			return &a.availableItems[idx]
 		}
-	})
+	case 1:
+		idx := a.appliedList.GetCurrentItem()
+		if idx >= 0 && idx < len(a.appliedItems) {
+			return &a.appliedItems[idx]
+		}
+	}
+	return nil
+}
 
-	// Preview
-	a.previewView = tview.NewTextView().
-		SetDynamicColors(true).
-		SetWordWrap(true).
-		SetScrollable(true)
-	a.previewView.SetBorder(true).
-		SetTitle(" Preview ").
-		SetTitleAlign(tview.AlignLeft).
-		SetBorderColor(tcell.ColorDefault)
+// copySelectedPath copies the selected item's path to the clipboard: the
+// global path from Available, the project symlink path from Applied.
+func (a *App) copySelectedPath() {
+	item := a.selectedItem()
+	if item == nil {
+		return
+	}
 
-	// Status bar
-	a.statusBar = tview.NewTextView().
-		SetTextAlign(tview.AlignLeft)
+	path := item.GlobalPath
+	if a.currentPanelIdx == 1 {
+		path = filepath.Join(a.categories[a.activeTabIdx].ProjectDir, item.linkName())
+	}
 
-	// Navigable panels (preview is not navigable)
-	a.panels = []tview.Primitive{a.availableList, a.appliedList}
+	if err := copyToClipboard(path); err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %v", err))
+		return
+	}
+	a.statusBar.SetText(fmt.Sprintf(" Copied path to clipboard: %s", tview.Escape(path)))
+}
 
-	// Layout
-	leftFlex := tview.NewFlex().SetDirection(tview.FlexRow).
-		AddItem(a.tabBar, 1, 0, false).
-		AddItem(a.availableList, 0, 1, true).
-		AddItem(a.appliedList, 0, 1, false)
+// maxCopyBytes caps the file contents copySelectedContent puts on the
+// clipboard.
+const maxCopyBytes = 1 << 20
+
+// copySelectedContent copies the selected item's file contents to the
+// clipboard; for a directory, its primary doc. Binary and very large files
+// are refused.
+func (a *App) copySelectedContent() {
+	item := a.selectedItem()
+	if item == nil {
+		return
+	}
 
-	mainFlex := tview.NewFlex().SetDirection(tview.FlexColumn).
-		AddItem(leftFlex, 0, 1, true).
-		AddItem(a.previewView, 0, 2, false)
+	path := item.GlobalPath
+	if a.currentPanelIdx == 1 {
+		path = filepath.Join(a.categories[a.activeTabIdx].ProjectDir, item.linkName())
+	}
+	if item.IsDir {
+		doc := primaryDoc(path, a.primaryDocs)
+		if doc == "" {
+			a.statusBar.SetText(fmt.Sprintf(" [yellow]%s has no %s to copy[-]", tview.Escape(item.Name), strings.Join(a.primaryDocs, " or ")))
+			return
+		}
+		path = doc
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
+		return
+	}
+	if info.Size() > maxCopyBytes {
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]%s is %s — too large to copy (limit %s)[-]", tview.Escape(filepath.Base(path)), formatSize(int(info.Size())), formatSize(maxCopyBytes)))
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
+		return
+	}
+	if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]%s looks binary — not copied[-]", tview.Escape(filepath.Base(path))))
+		return
+	}
+
+	if err := copyToClipboard(string(data)); err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %v", err))
+		return
+	}
+	a.statusBar.SetText(fmt.Sprintf(" Copied %s of %s to clipboard", formatSize(len(data)), tview.Escape(filepath.Base(path))))
+}
+
+// clipboardCommands lists the clipboard writers tried in order, per platform.
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"windows": {{"clip"}},
+	"linux": {
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+		{"clip.exe"}, // WSL
+	},
+}
+
+// copyToClipboard pipes text into the first available clipboard command.
+func copyToClipboard(text string) error {
+	for _, args := range clipboardCommands[runtime.GOOS] {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return fmt.Errorf("no clipboard command available")
+}
+
+// clipboardReaders lists the clipboard readers tried in order, per platform.
+var clipboardReaders = map[string][][]string{
+	"darwin":  {{"pbpaste"}},
+	"windows": {{"powershell", "-NoProfile", "-Command", "Get-Clipboard"}},
+	"linux": {
+		{"wl-paste", "--no-newline"},
+		{"xclip", "-selection", "clipboard", "-o"},
+		{"xsel", "--clipboard", "--output"},
+		{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"}, // WSL
+	},
+}
+
+// readClipboard returns the text of the clipboard from the first available
+// clipboard command.
+func readClipboard() (string, error) {
+	for _, args := range clipboardReaders[runtime.GOOS] {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		return string(out), err
+	}
+	return "", fmt.Errorf("no clipboard command available")
+}
 
-	rootFlex := tview.NewFlex().SetDirection(tview.FlexRow).
-		AddItem(mainFlex, 0, 1, true).
-		AddItem(a.statusBar, 1, 0, false)
+// pasteAsItem prompts for a file name and saves the clipboard text as a new
+// item of the active category, in the first store that has the category.
+func (a *App) pasteAsItem() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	text, err := readClipboard()
+	if err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] reading clipboard: %v", err))
+		return
+	}
+	if strings.TrimSpace(text) == "" {
+		a.statusBar.SetText(" [yellow]Clipboard is empty[-]")
+		return
+	}
 
-	a.setupKeybindings()
-	a.app.SetFocus(a.panels[0])
-	a.updateBorderColors()
+	cat := a.categories[a.activeTabIdx]
+	dir := cat.GlobalDirs[0].Path
+	a.showPrompt(" Paste as New Item ", "Name: ", "", func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
+		}
+		if err := validItemName(name); err != nil {
+			a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(err.Error()))
+			return
+		}
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = f.WriteString(text)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
+			return
+		}
 
-	a.pages = tview.NewPages().
-		AddPage("main", rootFlex, true, true)
-	a.app.SetRoot(a.pages, true)
+		a.refreshAll()
+		a.selectAvailable(path)
+		a.statusBar.SetText(fmt.Sprintf(" Saved clipboard to %s", tview.Escape(path)))
+	})
 }
 
-func (a *App) setupKeybindings() {
-	a.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		// Modal priority chain
-		if a.treeOpen {
-			if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
-				a.closeTree()
-				return nil
-			}
-			return event
+// duplicateItem copies the selected item's global entry (recursively for a
+// directory) to a new name in the same store and category, then offers to
+// open the copy in the editor.
+func (a *App) duplicateItem() {
+	item := a.selectedItem()
+	if item == nil || a.blockedByReadOnly() {
+		return
+	}
+	cat := a.categories[a.activeTabIdx]
+	ext := filepath.Ext(item.Name)
+	if item.IsDir {
+		ext = ""
+	}
+	suggested := strings.TrimSuffix(item.Name, ext) + "-copy" + ext
+
+	a.showPrompt(" Duplicate "+tview.Escape(item.DisplayName())+" ", "Name: ", suggested, func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
 		}
-		if a.helpOpen {
-			if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
-				a.closeHelp()
-				return nil
+		if err := validItemName(name); err != nil {
+			a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(err.Error()))
+			return
+		}
+		for _, dir := range cat.GlobalDirs {
+			if _, err := os.Lstat(filepath.Join(dir.Path, name)); err == nil {
+				a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %s already has an item named %s", tview.Escape(cat.Name), tview.Escape(name)))
+				return
 			}
-			return event
 		}
 
-		switch event.Key() {
-		case tcell.KeyRune:
-			switch event.Rune() {
-			case 'q':
-				a.app.Stop()
-				return nil
-			case '1':
-				a.focusPanel(0)
-				return nil
-			case '2':
-				a.focusPanel(1)
-				return nil
-			case 'h':
-				a.prevPanel()
-				return nil
-			case 'l':
-				a.nextPanel()
-				return nil
-			case 'j':
-				a.cursorDown()
-				return nil
-			case 'k':
-				a.cursorUp()
-				return nil
-			case 'J':
-				row, col := a.previewView.GetScrollOffset()
-				a.previewView.ScrollTo(row+1, col)
-				return nil
-			case 'K':
-				row, col := a.previewView.GetScrollOffset()
-				if row > 0 {
-					a.previewView.ScrollTo(row-1, col)
-				}
-				return nil
-			case '[':
-				a.prevTab()
-				return nil
-			case ']':
-				a.nextTab()
-				return nil
-			case ' ':
-				a.toggleSelected()
-				return nil
-			case 't':
-				a.showTree()
-				return nil
-			case '?':
-				a.showHelp()
-				return nil
-			}
-		case tcell.KeyEnter:
-			a.toggleSelected()
-			return nil
-		case tcell.KeyTab:
-			a.nextPanel()
-			return nil
-		case tcell.KeyBacktab:
-			a.prevPanel()
-			return nil
-		case tcell.KeyEsc:
-			a.app.Stop()
-			return nil
+		path := filepath.Join(filepath.Dir(item.GlobalPath), name)
+		if err := copyPath(canonicalPath(item.GlobalPath), path); err != nil {
+			os.RemoveAll(path)
+			a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
+			return
+		}
+
+		a.refreshAll()
+		a.selectAvailable(path)
+		a.statusBar.SetText(fmt.Sprintf(" Duplicated %s as %s", tview.Escape(item.Name), tview.Escape(name)))
+		edit := path
+		if item.IsDir {
+			edit = primaryDoc(path, a.primaryDocs)
+		}
+		if edit != "" {
+			a.showConfirm(" Duplicated ", fmt.Sprintf("Open %s in the editor?", name), func() { a.openInEditor(edit) })
 		}
-		return event
 	})
 }
 
-// --- Tab switching ---
-
-func (a *App) nextTab() {
-	a.activeTabIdx = (a.activeTabIdx + 1) % len(a.categories)
-	a.refreshAll()
+// validItemName rejects names that can't be a single entry of a category
+// directory, or that would be hidden from the lists.
+func validItemName(name string) error {
+	if name == "." || name == ".." || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\:*?"<>|`) || strings.ContainsRune(name, 0) {
+		return fmt.Errorf("invalid name %q", name)
+	}
+	return nil
 }
 
-func (a *App) prevTab() {
-	a.activeTabIdx = (a.activeTabIdx - 1 + len(a.categories)) % len(a.categories)
-	a.refreshAll()
+// selectAvailable focuses the Available panel and moves its cursor to the
+// item at path, if it is listed.
+func (a *App) selectAvailable(path string) {
+	a.focusPanel(0)
+	for row, idx := range a.availableRows {
+		if idx >= 0 && a.availableItems[idx].GlobalPath == path {
+			a.availableList.SetCurrentItem(row)
+			a.updatePreview()
+			break
+		}
+	}
 }
 
-// --- Panel navigation ---
+// --- Editor ---
 
-func (a *App) focusPanel(idx int) {
-	if idx >= 0 && idx < len(a.panels) {
-		a.currentPanelIdx = idx
-		a.app.SetFocus(a.panels[idx])
-		a.updateBorderColors()
-		a.updatePreview()
-		a.updateStatusBar()
+// editorCommand returns the command line items are opened with: the editor
+// setting, then $VISUAL, then $EDITOR, then vi.
+func (a *App) editorCommand() []string {
+	for _, editor := range []string{a.editor, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if fields := strings.Fields(editor); len(fields) > 0 {
+			return fields
+		}
 	}
+	return []string{"vi"}
 }
 
-func (a *App) nextPanel() {
-	a.focusPanel((a.currentPanelIdx + 1) % len(a.panels))
+// editSelected opens the selected item in the editor: the file itself, or a
+// directory's primary doc (the directory when it has none). From Applied it
+// opens the project entry, which for copies is not the global file.
+func (a *App) editSelected() {
+	item := a.selectedItem()
+	if item == nil || a.blockedByReadOnly() {
+		return
+	}
+	path := item.GlobalPath
+	if a.currentPanelIdx == 1 {
+		path = filepath.Join(a.categories[a.activeTabIdx].ProjectDir, item.linkName())
+	}
+	if item.IsDir {
+		if doc := primaryDoc(path, a.primaryDocs); doc != "" {
+			path = doc
+		}
+	}
+	a.openInEditor(path)
 }
 
-func (a *App) prevPanel() {
-	a.focusPanel((a.currentPanelIdx - 1 + len(a.panels)) % len(a.panels))
+// editCategory opens the active category's directory in the first store
+// that has it, for editors that open a directory as a project.
+func (a *App) editCategory() {
+	if a.blockedByReadOnly() {
+		return
+	}
+	a.openInEditor(a.categories[a.activeTabIdx].GlobalDirs[0].Path)
 }
 
-func (a *App) updateBorderColors() {
-	selectionColor := tcell.NewRGBColor(106, 159, 181)
+// openInEditor runs the editor on path. Terminal editors get the terminal
+// while the UI is suspended; with editor_detach the editor is only started,
+// for GUI editors that open their own window, and counts as pending for
+// confirm_quit until it exits. The lists are refreshed afterwards to pick up
+// any changes.
+func (a *App) openInEditor(path string) {
+	args := append(a.editorCommand(), path)
+	cmd := exec.Command(args[0], args[1:]...)
 
-	for _, p := range a.panels {
-		if box, ok := p.(interface {
-			SetBorderColor(tcell.Color) *tview.Box
-		}); ok {
-			box.SetBorderColor(tcell.ColorDefault)
-		}
-		if list, ok := p.(*tview.List); ok {
-			list.SetSelectedBackgroundColor(tcell.ColorDefault)
+	var err error
+	if a.editorDetach {
+		if err = cmd.Start(); err == nil {
+			done := a.beginPending("editing " + filepath.Base(path))
+			go func() {
+				cmd.Wait()
+				a.app.QueueUpdateDraw(func() {
+					done()
+					a.refreshAll()
+				})
+			}()
 		}
+	} else {
+		a.app.Suspend(func() {
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+			err = cmd.Run()
+		})
 	}
 
-	focused := a.panels[a.currentPanelIdx]
-	if box, ok := focused.(interface {
-		SetBorderColor(tcell.Color) *tview.Box
-	}); ok {
-		box.SetBorderColor(tcell.ColorGreen)
+	a.refreshAll()
+	if err != nil {
+		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %s: %v", tview.Escape(args[0]), err))
+		return
 	}
-	if list, ok := focused.(*tview.List); ok {
-		list.SetSelectedBackgroundColor(selectionColor)
+	if a.editorDetach {
+		a.statusBar.SetText(fmt.Sprintf(" Opened %s in %s", tview.Escape(path), tview.Escape(args[0])))
 	}
 }
 
-// --- Cursor movement ---
+// --- Preview search ---
 
-func (a *App) cursorDown() {
-	if list, ok := a.panels[a.currentPanelIdx].(*tview.List); ok {
-		count := list.GetItemCount()
-		current := list.GetCurrentItem()
-		if current < count-1 {
-			list.SetCurrentItem(current + 1)
-		}
-		a.updatePreview()
+func (a *App) showSearch() {
+	if a.previewHiddenHint() {
+		return
+	}
+	if a.previewContent == "" {
+		a.statusBar.SetText(" [yellow]Nothing to search in this preview[-]")
+		return
 	}
+	a.showPrompt(" Search Preview ", "/", a.searchQuery, a.runSearch)
 }
 
-func (a *App) cursorUp() {
-	if list, ok := a.panels[a.currentPanelIdx].(*tview.List); ok {
-		current := list.GetCurrentItem()
-		if current > 0 {
-			list.SetCurrentItem(current - 1)
-		}
-		a.updatePreview()
+// searchRegexp returns a case-insensitive matcher for the active query, or
+// nil when no search is active.
+func (a *App) searchRegexp() *regexp.Regexp {
+	if a.searchQuery == "" {
+		return nil
 	}
+	return regexp.MustCompile("(?i)" + regexp.QuoteMeta(a.searchQuery))
 }
 
-// --- Toggle (apply/remove) ---
-
-func (a *App) toggleSelected() {
-	switch a.currentPanelIdx {
-	case 0: // Available panel → apply
-		a.applySelected()
-	case 1: // Applied panel → remove
-		a.removeSelected()
+func (a *App) runSearch(query string) {
+	a.clearSearch()
+	a.searchQuery = query
+	a.renderPreview()
+	if query == "" {
+		a.updateStatusBar()
+		return
+	}
+	if a.searchMatches == 0 {
+		a.statusBar.SetText(fmt.Sprintf(" [yellow]No matches for %s[-]", tview.Escape(strconv.Quote(query))))
+		return
 	}
+	a.jumpToMatch()
 }
 
-func (a *App) applySelected() {
-	idx := a.availableList.GetCurrentItem()
-	if idx < 0 || idx >= len(a.availableItems) {
+// nextMatch moves to the next (dir=1) or previous (dir=-1) search match,
+// wrapping around at either end.
+func (a *App) nextMatch(dir int) {
+	if a.searchMatches == 0 {
 		return
 	}
+	a.searchIdx = (a.searchIdx + dir + a.searchMatches) % a.searchMatches
+	a.jumpToMatch()
+}
 
-	cat := a.categories[a.activeTabIdx]
-	item := a.availableItems[idx]
+func (a *App) jumpToMatch() {
+	id := fmt.Sprintf("match-%d", a.searchIdx)
+	a.previewView.Highlight(id).ScrollToHighlight()
+	if a.zoomOpen {
+		a.zoomView.Highlight(id).ScrollToHighlight()
+	}
+	a.statusBar.SetText(fmt.Sprintf(" Match %d/%d for %s  %s",
+		a.searchIdx+1, a.searchMatches, tview.Escape(strconv.Quote(a.searchQuery)), tview.Escape("[n/N] next/prev  [esc] clear")))
+}
 
-	if err := os.MkdirAll(cat.ProjectDir, 0755); err != nil {
-		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %v", err))
-		return
+func (a *App) clearSearch() {
+	a.searchQuery = ""
+	a.searchMatches = 0
+	a.searchIdx = 0
+	a.previewView.Highlight()
+	if a.zoomOpen {
+		a.zoomView.Highlight()
 	}
+}
 
-	target := filepath.Join(cat.ProjectDir, item.Name)
-	if err := os.Symlink(item.GlobalPath, target); err != nil {
-		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %v", err))
+// --- Zoomed preview modal ---
+
+// showZoom expands the current preview into a near-fullscreen modal with its
+// own scrolling, search, and line-number toggle.
+func (a *App) showZoom() {
+	if a.selectedItem() == nil || a.previewHiddenHint() {
 		return
 	}
+	a.zoomOpen = true
 
-	a.refreshAll()
+	a.zoomView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetRegions(true).
+		SetScrollable(true)
+	a.zoomView.SetBackgroundColor(a.previewBackground())
+	a.zoomView.SetBorder(true).
+		SetTitle(tview.Escape(" Preview — [/] search  [n/N] next/prev  [#] line numbers  [esc] close ")).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+	a.renderZoom()
+
+	a.zoomFrame = modal(a.zoomView, a.screenWidth-4, a.screenHeight-2)
+	a.pages.AddPage("zoom", a.zoomFrame, true, true)
+	a.app.SetFocus(a.zoomView)
+	a.updateStatusBar()
 }
 
-func (a *App) removeSelected() {
-	idx := a.appliedList.GetCurrentItem()
-	if idx < 0 || idx >= len(a.appliedItems) {
+// renderZoom refreshes the zoomed view from the main preview's state.
+func (a *App) renderZoom() {
+	if a.previewContent == "" {
+		// Tree or error previews have no raw content; mirror the pane as-is.
+		a.zoomView.SetText(a.previewView.GetText(false))
 		return
 	}
+	a.renderPreview()
+	if a.searchMatches > 0 && a.searchQuery != "" {
+		a.zoomView.Highlight(fmt.Sprintf("match-%d", a.searchIdx))
+	}
+}
 
-	cat := a.categories[a.activeTabIdx]
-	item := a.appliedItems[idx]
+func (a *App) closeZoom() {
+	a.zoomOpen = false
+	a.zoomView = nil
+	a.zoomFrame = nil
+	a.pages.RemovePage("zoom")
+	a.restoreFocus()
+	a.updateBorderColors()
+	a.updateStatusBar()
+}
 
-	target := filepath.Join(cat.ProjectDir, item.Name)
-	if err := os.Remove(target); err != nil {
-		a.statusBar.SetText(fmt.Sprintf(" [red]Error:[-] %v", err))
+// --- Tree modal ---
+
+func (a *App) showTree() {
+	item := a.selectedItem()
+	if item == nil || !item.IsDir {
 		return
 	}
 
-	a.refreshAll()
-}
+	a.treeOpen = true
+	a.treeItem = item
+	a.treeDepth = defaultTreeDepth
 
-// --- Refresh ---
+	a.treeView = tview.NewTreeView().
+		SetGraphicsColor(tcell.ColorDarkGray).
+		SetSelectedFunc(a.selectTreeNode).
+		SetChangedFunc(a.previewTreeNode)
+	a.treePreview = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	a.treePreview.SetBorder(true).
+		SetBorderColor(tcell.ColorDarkGray)
+	a.renderTree()
 
-func (a *App) refreshAll() {
-	a.loadItems()
-	a.refreshAvailableList()
-	a.refreshAppliedList()
-	a.updateTabBar()
-	a.updatePanelTitles()
-	a.updatePreview()
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[darkgray]Enter fold/unfold or open file, J/K scroll, +/- depth, Esc/q close[-]")
+	body := tview.NewFlex().
+		AddItem(a.treeView, 0, 2, true).
+		AddItem(a.treePreview, 0, 3, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(body, 0, 1, true).
+		AddItem(hint, 1, 0, false)
+	layout.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s — Tree ", a.treeItem.Name)).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	width, height := a.treeModalSize()
+	a.treeFrame = modal(layout, width, height)
+	a.pages.AddPage("tree", a.treeFrame, true, true)
+	a.app.SetFocus(a.treeView)
 	a.updateStatusBar()
-	a.updateBorderColors()
 }
 
-func (a *App) refreshAvailableList() {
-	currentIdx := a.availableList.GetCurrentItem()
-	a.availableList.Clear()
-
-	for _, item := range a.availableItems {
-		prefix := "  "
-		displayName := item.DisplayName()
-		a.availableList.AddItem(prefix+displayName, "", 0, nil)
-	}
+// treeModalSize returns the tree modal's width and height for the current
+// screen.
+func (a *App) treeModalSize() (width, height int) {
+	return min(110, max(a.screenWidth-4, 60)), min(35, max(a.screenHeight-4, 15))
+}
 
-	if currentIdx >= len(a.availableItems) {
-		currentIdx = len(a.availableItems) - 1
-	}
-	if currentIdx >= 0 {
-		a.availableList.SetCurrentItem(currentIdx)
-	}
+// treeEntry is the reference stored on each tree modal node.
+type treeEntry struct {
+	path   string
+	isDir  bool
+	loaded bool   // children have been added; directories load on first unfold
+	link   string // symlink target; linked directories are never expanded
 }
 
-func (a *App) refreshAppliedList() {
-	currentIdx := a.appliedList.GetCurrentItem()
-	a.appliedList.Clear()
+// renderTree rebuilds the tree modal with directories expanded to the
+// current depth.
+func (a *App) renderTree() {
+	root := tview.NewTreeNode(fmt.Sprintf("[cyan::b]%s/[-:-:-] [darkgray](depth %d)[-]", tview.Escape(a.treeItem.Name), a.treeDepth)).
+		SetReference(&treeEntry{path: a.treeItem.GlobalPath, isDir: true})
+	a.treeFilter = a.newTreeIgnore(a.treeItem.GlobalPath)
+	a.loadTreeNode(root, 0, a.treeDepth)
+	a.treeView.SetRoot(root).SetCurrentNode(root)
+	a.previewTreeNode(root)
+}
 
-	for _, item := range a.appliedItems {
-		prefix := "[green]+[-] "
-		displayName := item.DisplayName()
-		a.appliedList.AddItem(prefix+displayName, "", 0, nil)
+// loadTreeNode adds the entries of a directory node as children. Directories
+// within maxDepth are loaded and unfolded too; deeper ones are left folded and
+// load when first unfolded.
+func (a *App) loadTreeNode(node *tview.TreeNode, depth, maxDepth int) {
+	entry := node.GetReference().(*treeEntry)
+	entry.loaded = true
+	for _, child := range a.treeFilter.entries(entry.path) {
+		path := filepath.Join(entry.path, child.Name())
+		if target, isDir, ok := symlinkTarget(path); ok {
+			name := tview.Escape(child.Name())
+			if isDir {
+				name = "[cyan]" + name + "/[-]"
+			}
+			node.AddChild(tview.NewTreeNode(name + linkSuffix(target)).
+				SetReference(&treeEntry{path: path, isDir: isDir, loaded: isDir, link: target}))
+			continue
+		}
+		if !child.IsDir() {
+			node.AddChild(tview.NewTreeNode(tview.Escape(child.Name())).SetReference(&treeEntry{path: path}))
+			continue
+		}
+		childNode := tview.NewTreeNode(fmt.Sprintf("[cyan]%s/[-]%s", tview.Escape(child.Name()), a.dirCountLabel(a.treeFilter, path))).
+			SetReference(&treeEntry{path: path, isDir: true}).
+			SetExpanded(false)
+		if depth < maxDepth {
+			a.loadTreeNode(childNode, depth+1, maxDepth)
+			childNode.SetExpanded(true)
+		}
+		node.AddChild(childNode)
 	}
+}
 
-	if currentIdx >= len(a.appliedItems) {
-		currentIdx = len(a.appliedItems) - 1
+// selectTreeNode folds or unfolds a directory node, and opens a file node
+// in the preview pane.
+func (a *App) selectTreeNode(node *tview.TreeNode) {
+	entry, ok := node.GetReference().(*treeEntry)
+	if !ok {
+		return
 	}
-	if currentIdx >= 0 {
-		a.appliedList.SetCurrentItem(currentIdx)
+	if entry.isDir {
+		if !entry.loaded {
+			a.loadTreeNode(node, 0, 0)
+		}
+		node.SetExpanded(!node.IsExpanded())
+		return
+	}
+
+	item := a.treeItem
+	a.closeTree()
+	a.previewNestedFile(item, entry.path)
+	if a.compact {
+		a.showPreview()
 	}
 }
 
-func (a *App) updateTabBar() {
-	var parts []string
-	for i, cat := range a.categories {
-		name := strings.Title(cat.Name)
-		if i == a.activeTabIdx {
-			parts = append(parts, fmt.Sprintf("[green::b] %s [-:-:-]", name))
-		} else {
-			parts = append(parts, fmt.Sprintf("[darkgray] %s [-]", name))
-		}
+// previewTreeNode shows the file under the tree cursor in the modal's
+// preview column.
+func (a *App) previewTreeNode(node *tview.TreeNode) {
+	entry, ok := node.GetReference().(*treeEntry)
+	if !ok {
+		return
+	}
+	a.treePreview.SetTitle(" " + tview.Escape(filepath.Base(entry.path)) + " ")
+	if entry.isDir && entry.link != "" {
+		a.treePreview.SetText(fmt.Sprintf("[darkgray]Symlink to %s (not followed)[-]", tview.Escape(entry.link)))
+		return
+	}
+	if entry.isDir {
+		a.treePreview.SetText("[darkgray]Enter to fold or unfold[-]")
+		return
+	}
+	content, err := a.readPreviewFile(entry.path)
+	if err != nil {
+		a.treePreview.SetText(fmt.Sprintf("[red]Error reading file:[-] %v", err))
+		return
 	}
-	a.tabBar.SetText(strings.Join(parts, "│"))
+	a.treePreview.SetText(a.highlightCode(content, detectLanguage(entry.path))).ScrollToBeginning()
 }
 
-func (a *App) updatePanelTitles() {
-	catName := strings.Title(a.categories[a.activeTabIdx].Name)
-	a.availableList.SetTitle(fmt.Sprintf(" [1] Available %s ", catName))
-	a.appliedList.SetTitle(fmt.Sprintf(" [2] Applied %s ", catName))
+// previewNestedFile shows a file inside a directory item in the preview
+// pane, titled with its path relative to the item's parent.
+func (a *App) previewNestedFile(item *Item, path string) {
+	name, err := filepath.Rel(filepath.Dir(item.GlobalPath), path)
+	if err != nil {
+		name = filepath.Base(path)
+	}
+	a.rememberScroll()
+	a.previewView.Clear()
+	a.clearSearch()
+	a.showFilePreview(&Item{Name: name, Warning: item.Warning}, path)
+	a.previewPathKey = path
+	a.restoreScroll()
 }
 
-func (a *App) updateStatusBar() {
-	a.statusBar.SetText(" [1-2] panels  [j/k] navigate  [J/K] scroll preview  [space/enter] toggle  [/] tabs  [t] tree  [?] help  [q] quit")
+// changeTreeDepth adjusts the tree modal's depth by delta within bounds.
+func (a *App) changeTreeDepth(delta int) {
+	depth := min(max(a.treeDepth+delta, 0), maxTreeDepth)
+	if depth != a.treeDepth {
+		a.treeDepth = depth
+		a.renderTree()
+	}
 }
 
-// --- Preview ---
+func (a *App) closeTree() {
+	a.treeOpen = false
+	a.treeView = nil
+	a.treePreview = nil
+	a.treeItem = nil
+	a.treeFrame = nil
+	a.pages.RemovePage("tree")
+	a.restoreFocus()
+	a.updateBorderColors()
+	a.updateStatusBar()
+}
 
-func (a *App) updatePreview() {
-	a.previewView.Clear()
+// --- Outline modal ---
 
-	var item *Item
-	switch a.currentPanelIdx {
-	case 0:
-		idx := a.availableList.GetCurrentItem()
-		if idx >= 0 && idx < len(a.availableItems) {
-			item = &a.availableItems[idx]
-		}
-	case 1:
-		idx := a.appliedList.GetCurrentItem()
-		if idx >= 0 && idx < len(a.appliedItems) {
-			item = &a.appliedItems[idx]
-		}
-	default:
-		// Preview panel focused — show whatever was last shown
+// showOutline parses the previewed JSON or YAML file and shows its structure
+// as a collapsible tree. Files that don't parse open in the full-screen
+// preview instead.
+func (a *App) showOutline() {
+	path := a.previewPathKey
+	if a.selectedItem() == nil || path == "" {
 		return
 	}
-
-	if item == nil {
-		a.previewView.SetText("[darkgray]No item selected[-]")
+	if lang := detectLanguage(path); lang != "json" && lang != "yaml" {
+		a.statusBar.SetText(" [yellow]Outline is only available for JSON and YAML files[-]")
 		return
 	}
-
-	if item.IsDir {
-		a.showDirectoryPreview(item)
-	} else {
-		a.showFilePreview(item)
-	}
-}
-
-func (a *App) showFilePreview(item *Item) {
-	data, err := os.ReadFile(item.GlobalPath)
+	data, err := os.ReadFile(path)
 	if err != nil {
-		a.previewView.SetText(fmt.Sprintf("[red]Error reading file:[-] %v", err))
+		a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
 		return
 	}
-
-	content := string(data)
-	if len(data) > 100*1024 {
-		content = string(data[:100*1024])
-		content += "\n\n[darkgray]--- truncated (>100KB) ---[-]"
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		a.showZoom()
+		if err != nil {
+			a.statusBar.SetText(" [yellow]Can't outline " + tview.Escape(filepath.Base(path)) + ":[-] " + tview.Escape(err.Error()))
+		}
+		return
 	}
 
-	lang := detectLanguage(item.Name)
-	highlighted := highlightCode(content, lang)
-	a.previewView.SetText(fmt.Sprintf("[cyan::b]%s[-:-:-]\n\n%s", item.Name, highlighted))
+	a.outlineOpen = true
+	root := outlineNode(tview.Escape(filepath.Base(path)), doc.Content[0], 0)
+	tree := tview.NewTreeView().
+		SetGraphicsColor(tcell.ColorDarkGray).
+		SetRoot(root).
+		SetCurrentNode(root).
+		SetSelectedFunc(func(node *tview.TreeNode) { node.SetExpanded(!node.IsExpanded()) })
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[darkgray]Enter fold/unfold, Esc/q close[-]")
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tree, 0, 1, true).
+		AddItem(hint, 1, 0, false)
+	layout.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s — Outline ", tview.Escape(filepath.Base(path)))).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	width, height := a.treeModalSize()
+	a.outlineFrame = modal(layout, width, height)
+	a.pages.AddPage("outline", a.outlineFrame, true, true)
+	a.app.SetFocus(tree)
+	a.updateStatusBar()
 }
 
-func (a *App) showDirectoryPreview(item *Item) {
-	// Check for SKILL.md
-	skillPath := filepath.Join(item.GlobalPath, "SKILL.md")
-	if data, err := os.ReadFile(skillPath); err == nil {
-		content := string(data)
-		if len(data) > 100*1024 {
-			content = string(data[:100*1024])
-			content += "\n\n[darkgray]--- truncated (>100KB) ---[-]"
+// outlineUnfoldDepth is how many levels of an outline start unfolded.
+const outlineUnfoldDepth = 2
+
+// outlineNode builds the tree node for a parsed value labelled label.
+// Scalars show their value inline; mappings and sequences show their size
+// and hold their entries as children.
+func outlineNode(label string, value *yaml.Node, depth int) *tview.TreeNode {
+	node := tview.NewTreeNode(label).SetExpanded(depth < outlineUnfoldDepth)
+	switch value.Kind {
+	case yaml.MappingNode:
+		node.SetText(fmt.Sprintf("%s [darkgray]{%d}[-]", label, len(value.Content)/2))
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			key := "[cyan]" + tview.Escape(value.Content[i].Value) + "[-]"
+			node.AddChild(outlineNode(key, value.Content[i+1], depth+1))
 		}
-		highlighted := highlightCode(content, "markdown")
-		a.previewView.SetText(fmt.Sprintf("[cyan::b]%s/[-:-:-] [darkgray](SKILL.md)[-]\n\n%s", item.Name, highlighted))
-		return
+	case yaml.SequenceNode:
+		node.SetText(fmt.Sprintf("%s [darkgray][%d][-]", label, len(value.Content)))
+		for i, child := range value.Content {
+			node.AddChild(outlineNode(fmt.Sprintf("[darkgray]%d[-]", i), child, depth+1))
+		}
+	case yaml.AliasNode:
+		node.SetText(fmt.Sprintf("%s: [darkgray]*%s[-]", label, tview.Escape(value.Value)))
+	default:
+		text, _, cut := strings.Cut(value.Value, "\n")
+		if cut {
+			text += "…"
+		}
+		node.SetText(fmt.Sprintf("%s: %s", label, tview.Escape(text)))
 	}
+	return node
+}
 
-	// Fallback: directory listing
-	var b strings.Builder
-	b.WriteString(fmt.Sprintf("[cyan::b]%s/[-:-:-]\n\n", item.Name))
-	a.buildTree(&b, item.GlobalPath, "", 0)
-	a.previewView.SetText(b.String())
+func (a *App) closeOutline() {
+	a.outlineOpen = false
+	a.outlineFrame = nil
+	a.pages.RemovePage("outline")
+	a.restoreFocus()
+	a.updateBorderColors()
+	a.updateStatusBar()
 }
 
-func (a *App) buildTree(b *strings.Builder, dir, prefix string, depth int) {
-	if depth > 3 {
-		b.WriteString(prefix + "[darkgray]...[-]\n")
-		return
-	}
+// --- Link chain modal ---
 
-	entries, err := os.ReadDir(dir)
-	if err != nil {
+// maxLinkHops bounds linkChain, like the kernel's limit on nested links.
+const maxLinkHops = 40
+
+// showLinkChain lists each hop from the selected applied item's project
+// path to the real file it ends at, or to where the chain breaks.
+func (a *App) showLinkChain() {
+	item := a.selectedItem()
+	if item == nil || a.currentPanelIdx != 1 {
+		a.statusBar.SetText(" [yellow]Select an item in the Applied panel to show its link chain[-]")
 		return
 	}
+	lines := linkChain(filepath.Join(a.categories[a.activeTabIdx].ProjectDir, item.linkName()))
+	a.chainOpen = true
 
-	for i, entry := range entries {
-		if strings.HasPrefix(entry.Name(), ".") {
-			continue
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(strings.Join(lines, "\n"))
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Key() == tcell.KeyEnter || event.Rune() == 'q' {
+			a.closeLinkChain()
+			return nil
 		}
-		isLast := i == len(entries)-1
-		connector := "├── "
-		childPrefix := prefix + "│   "
-		if isLast {
-			connector = "└── "
-			childPrefix = prefix + "    "
+		return event
+	})
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[darkgray]Esc/q close[-]")
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(view, 0, 1, true).
+		AddItem(hint, 1, 0, false)
+	layout.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s — Link Chain ", tview.Escape(item.DisplayName()))).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	a.pages.AddPage("chain", modal(layout, min(100, max(a.screenWidth-4, 40)), min(len(lines)+3, 20)), true, true)
+	a.app.SetFocus(view)
+}
+
+// linkChain follows path one symlink at a time and returns a line per hop,
+// ending at a real file or directory, a missing target, or a loop.
+func linkChain(path string) []string {
+	var lines []string
+	seen := map[string]bool{}
+	for hop := 0; ; hop++ {
+		arrow := "  "
+		if hop > 0 {
+			arrow = "→ "
 		}
+		info, err := os.Lstat(path)
+		if err != nil {
+			return append(lines, arrow+"[red]"+tview.Escape(path)+"  (missing — the chain breaks here)[-]")
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			kind := "file"
+			if info.IsDir() {
+				kind = "directory"
+			}
+			return append(lines, arrow+"[green]"+tview.Escape(path)+"[-]  [darkgray]("+kind+")[-]")
+		}
+		if seen[path] || hop == maxLinkHops {
+			return append(lines, arrow+"[red]"+tview.Escape(path)+"  (symlink loop)[-]")
+		}
+		seen[path] = true
 
-		if entry.IsDir() {
-			b.WriteString(fmt.Sprintf("%s%s[cyan]%s/[-]\n", prefix, connector, entry.Name()))
-			a.buildTree(b, filepath.Join(dir, entry.Name()), childPrefix, depth+1)
-		} else {
-			b.WriteString(fmt.Sprintf("%s%s%s\n", prefix, connector, entry.Name()))
+		target, err := os.Readlink(path)
+		if err != nil {
+			return append(lines, arrow+"[red]"+tview.Escape(path)+"  ("+tview.Escape(err.Error())+")[-]")
+		}
+		lines = append(lines, arrow+tview.Escape(path)+"  [darkgray](symlink to "+tview.Escape(target)+")[-]")
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
 		}
+		path = target
 	}
 }
 
-// --- Tree modal ---
+func (a *App) closeLinkChain() {
+	a.chainOpen = false
+	a.pages.RemovePage("chain")
+	a.restoreFocus()
+	a.updateBorderColors()
+}
 
-func (a *App) showTree() {
-	// Get the currently selected item
-	var item *Item
-	switch a.currentPanelIdx {
-	case 0:
-		idx := a.availableList.GetCurrentItem()
-		if idx >= 0 && idx < len(a.availableItems) {
-			item = &a.availableItems[idx]
-		}
-	case 1:
-		idx := a.appliedList.GetCurrentItem()
-		if idx >= 0 && idx < len(a.appliedItems) {
-			item = &a.appliedItems[idx]
+// --- Path info modal ---
+
+// showPathInfo lists where lazyclaude reads from and writes to: the stores,
+// the project, and the active category's directories on both sides.
+func (a *App) showPathInfo() {
+	cat := a.categories[a.activeTabIdx]
+	labels := storeLabels(a.globalRoots, a.projectStore)
+	dim := func(s string) string { return "  [darkgray]" + tview.Escape(s) + "[-]" }
+
+	lines := []string{"[::b]Stores[::-]"}
+	for i, root := range a.globalRoots {
+		line := "  " + tview.Escape(root)
+		if labels[i] != "" && labels[i] != root {
+			line += dim("(" + labels[i] + ")")
 		}
+		lines = append(lines, line)
 	}
+	lines = append(lines, "", "[::b]Project[::-]",
+		"  "+tview.Escape(filepath.Dir(a.claudeDir)),
+		"  "+tview.Escape(a.claudeDir)+dim("(claude_dir)"))
 
-	if item == nil || !item.IsDir {
-		return
+	lines = append(lines, "", fmt.Sprintf("[::b]Category %s[::-]", tview.Escape(cat.Name)))
+	for _, dir := range cat.GlobalDirs {
+		line := "  " + tview.Escape(dir.Path)
+		if dir.Origin != "" {
+			line += dim("(" + dir.Origin + ")")
+		}
+		lines = append(lines, line)
 	}
+	into := "items are applied here, by " + string(cat.Strategy)
+	if linkedCategory(cat) {
+		into = "linked to the store as a whole"
+	}
+	lines = append(lines, "→ "+tview.Escape(cat.ProjectDir)+dim("("+into+")"),
+		fmt.Sprintf("  %d available, %d applied", len(a.availableItems), len(a.appliedItems)))
 
-	a.treeOpen = true
-
-	var b strings.Builder
-	b.WriteString(fmt.Sprintf("[cyan::b]%s/[-:-:-]\n\n", item.Name))
-	a.buildTree(&b, item.GlobalPath, "", 0)
-	b.WriteString("\n[darkgray]Press Escape or q to close[-]")
+	if dir, err := configDir(); err == nil {
+		lines = append(lines, "", "[::b]Config[::-]", "  "+tview.Escape(dir))
+	}
+	a.pathsOpen = true
 
-	treeText := tview.NewTextView().
+	view := tview.NewTextView().
 		SetDynamicColors(true).
 		SetScrollable(true).
-		SetText(b.String())
-	treeText.SetBorder(true).
-		SetTitle(fmt.Sprintf(" %s — Tree ", item.Name)).
+		SetText(strings.Join(lines, "\n"))
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Key() == tcell.KeyEnter || event.Rune() == 'q' {
+			a.closePathInfo()
+			return nil
+		}
+		return event
+	})
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[darkgray]Esc/q close[-]")
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(view, 0, 1, true).
+		AddItem(hint, 1, 0, false)
+	layout.SetBorder(true).
+		SetTitle(" Paths ").
 		SetTitleAlign(tview.AlignCenter).
 		SetBorderColor(tcell.ColorGreen)
 
-	a.pages.AddPage("tree", modal(treeText, 60, 25), true, true)
-	a.app.SetFocus(treeText)
+	a.pages.AddPage("paths", modal(layout, min(100, max(a.screenWidth-4, 40)), min(len(lines)+3, a.screenHeight-2)), true, true)
+	a.app.SetFocus(view)
 }
 
-func (a *App) closeTree() {
-	a.treeOpen = false
-	a.pages.RemovePage("tree")
-	a.app.SetFocus(a.panels[a.currentPanelIdx])
+func (a *App) closePathInfo() {
+	a.pathsOpen = false
+	a.pages.RemovePage("paths")
+	a.restoreFocus()
+	a.updateBorderColors()
+}
+
+// --- Compact preview page ---
+
+// showPreview displays the preview pane full-screen. Only used in compact
+// mode, where the preview is not part of the main layout.
+func (a *App) showPreview() {
+	a.previewOpen = true
+	a.pages.AddPage("preview", a.previewView, true, true)
+	a.previewView.SetBorderColor(tcell.ColorGreen)
+	a.app.SetFocus(a.previewView)
+}
+
+func (a *App) closePreview() {
+	a.previewOpen = false
+	a.pages.RemovePage("preview")
+	a.previewView.SetBorderColor(tcell.ColorDefault)
+	a.restoreFocus()
 	a.updateBorderColors()
 }
 
@@ -782,28 +7137,73 @@ func (a *App) closeTree() {
 func (a *App) showHelp() {
 	a.helpOpen = true
 
+	title := "[yellow::b]LazyClaude — Help[-:-:-]"
+	if a.readOnly {
+		title += "  [red::b]READ-ONLY[-:-:-]"
+	}
+
 	helpText := tview.NewTextView().
 		SetDynamicColors(true).
 		SetScrollable(true).
-		SetText(`[yellow::b]LazyClaude — Help[-:-:-]
+		SetText(title + `
 
 [green]Navigation:[-]
   1, 2          Jump to panel
+  3             Focus preview (d/u half page, g/G ends)
   Tab / S-Tab   Cycle panels
   h / l         Prev / Next panel
-  j / k         Move cursor
+  j / k         Move cursor (5j moves five)
+  g / {n}g      Go to the first item / item n
+  '             Number visible items, type one to jump
   J / K         Scroll preview
+  f             Full-screen preview (# line numbers)
+  o             Outline of a JSON / YAML file
+  v             Split: global vs project (Applied)
+  < / >         Widen / narrow preview
+  =             Applied panel: even / small / collapsed
+  /             Search preview
+  n / N         Next / Prev match (Esc clears)
 
 [green]Tabs:[-]
   [ / ]         Prev / Next category
+  T             Go to category (type to filter)
 
 [green]Actions:[-]
   Space / Enter Apply or remove item
                 (Available → apply, Applied → remove)
-  t             Show folder tree (directories)
+  → / ←         Apply from Available / Remove from Applied
+  m             Apply under a different name
+  P             Apply to sibling projects
+  u             Undo last apply / remove
+  H             Items changed this session
+  c             Copy item path to clipboard
+  C             Copy item contents to clipboard
+  i             Symlink chain of an applied item
+  I             Store and project paths
+  V             New item from clipboard text
+  D             Duplicate item under a new name
+  e / E         Open item / category dir in editor
+  A / X         Apply all / Remove all
+  L             Link / unlink whole category dir
+  *             Star / unstar item
+  F             Apply all starred items
+  b             Review starred items in one view
+  G             Group Available (letter / type / off)
+  d             Show / hide item descriptions
+  w             Show item paths / names
+  S             Reverse sort order
+  U             Sort Available by use across projects
+  y             Apply items listed in lazyclaude.yaml
+  Y             Save applied items to lazyclaude.yaml
+  B             Export applied items to a bundle dir
+  t             Browse folder tree (directories)
+  p             Hide / show preview (narrow: open it)
 
 [green]Meta:[-]
   q / Esc       Quit
+  r / F5        Rescan categories and items
+  R             Reload config.yaml and keys.yaml
+  : / Ctrl-P    Command palette
   ?             This help
 
 [darkgray]Press Escape or q to close[-]`)
@@ -813,14 +7213,181 @@ func (a *App) showHelp() {
 		SetTitleAlign(tview.AlignCenter).
 		SetBorderColor(tcell.ColorGreen)
 
-	a.pages.AddPage("help", modal(helpText, 55, 22), true, true)
+	a.pages.AddPage("help", modal(helpText, 55, 62), true, true)
 	a.app.SetFocus(helpText)
+	a.updateStatusBar()
 }
 
 func (a *App) closeHelp() {
 	a.helpOpen = false
 	a.pages.RemovePage("help")
-	a.app.SetFocus(a.panels[a.currentPanelIdx])
+	a.restoreFocus()
+	a.updateBorderColors()
+	a.updateStatusBar()
+}
+
+// --- First-run welcome ---
+
+// setupCompleteName is the file in the config directory recording that the
+// first-run welcome has been shown.
+const setupCompleteName = "setup-complete"
+
+// setupComplete reports whether the first-run welcome was already shown.
+func setupComplete() bool {
+	dir, err := configDir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, setupCompleteName))
+	return err == nil
+}
+
+// markSetupComplete records that the first-run welcome has been shown.
+func markSetupComplete() error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, setupCompleteName), nil, 0644)
+}
+
+// showWelcome introduces the Available/Applied model on a first run, with an
+// empty store. Enter continues to the help; it is not shown again.
+func (a *App) showWelcome() {
+	a.welcomeOpen = true
+	if err := markSetupComplete(); err != nil {
+		a.statusBar.SetText(" [red]Error:[-] " + tview.Escape(describeFSError(err)))
+	}
+
+	text := fmt.Sprintf(`[yellow::b]Welcome to LazyClaude[-:-:-]
+
+Your global store is [green]%s[-]. Each directory in it is a category, shown as a tab; put agents, commands and skills there.
+
+[::b]Available[::-] lists the store's items that this project doesn't use yet. [::b]Applied[::-] lists those linked into [green]%s[-].
+
+Press [green]Space[-] to move the selected item across: applying links it into the project, removing deletes only the link. [green]u[-] undoes, [green]e[-] edits an item and [green]E[-] opens the category directory.
+
+[darkgray]Enter: show all keys   Esc: start[-]`,
+		tview.Escape(a.globalRoots[0]), tview.Escape(a.claudeDir))
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetText(text)
+	view.SetBorder(true).
+		SetTitle(" Getting Started ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEnter:
+			a.closeWelcome()
+			a.showHelp()
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+			a.closeWelcome()
+		}
+		return nil
+	})
+
+	a.pages.AddPage("welcome", modal(view, 64, 17), true, true)
+	a.app.SetFocus(view)
+}
+
+func (a *App) closeWelcome() {
+	a.welcomeOpen = false
+	a.pages.RemovePage("welcome")
+	a.restoreFocus()
+	a.updateBorderColors()
+}
+
+// --- Confirm modal ---
+
+// defaultConfirm lists the actions that ask before running unless the
+// confirm setting says otherwise.
+var defaultConfirm = map[string]bool{
+	"applyAll":           true,
+	"removeAll":          true,
+	"writeProjectConfig": true,
+	"linkCategory":       true, // only when unlinking
+}
+
+// confirm runs onYes for action, first asking with showConfirm if the
+// confirm setting (or defaultConfirm) says the action needs confirmation.
+func (a *App) confirm(action, title, message string, onYes func()) {
+	ask, ok := a.confirmActions[action]
+	if !ok {
+		ask = defaultConfirm[action]
+	}
+	if !ask {
+		onYes()
+		return
+	}
+	a.showConfirm(title, message, onYes)
+}
+
+// showConfirm opens a yes/no modal that runs onYes when confirmed.
+func (a *App) showConfirm(title, message string, onYes func()) {
+	a.confirmOpen = true
+	a.confirmAction = onYes
+
+	confirmText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText(fmt.Sprintf("\n%s\n\n[green]y[-] yes    [red]n[-] no", tview.Escape(message)))
+	confirmText.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+
+	a.pages.AddPage("confirm", modal(confirmText, 50, strings.Count(message, "\n")+7), true, true)
+	a.app.SetFocus(confirmText)
+}
+
+func (a *App) closeConfirm() {
+	a.confirmOpen = false
+	a.confirmAction = nil
+	a.pages.RemovePage("confirm")
+	a.restoreFocus()
+	a.updateBorderColors()
+}
+
+// --- Prompt modal ---
+
+// showPrompt opens a single-line input modal. onDone receives the entered
+// text when Enter is pressed; Esc cancels.
+func (a *App) showPrompt(title, label, initial string, onDone func(string)) {
+	a.promptOpen = true
+
+	input := tview.NewInputField().
+		SetLabel(label).
+		SetText(initial).
+		SetFieldBackgroundColor(tcell.ColorDefault)
+	input.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorGreen)
+	input.SetDoneFunc(func(key tcell.Key) {
+		text := input.GetText()
+		a.closePrompt()
+		if key == tcell.KeyEnter {
+			onDone(text)
+		}
+	})
+
+	a.pages.AddPage("prompt", modal(input, 50, 3), true, true)
+	a.app.SetFocus(input)
+}
+
+func (a *App) closePrompt() {
+	a.promptOpen = false
+	a.pages.RemovePage("prompt")
+	if a.zoomOpen {
+		a.app.SetFocus(a.zoomView)
+		return
+	}
+	a.restoreFocus()
 	a.updateBorderColors()
 }
 
@@ -834,37 +7401,217 @@ func modal(content tview.Primitive, width, height int) tview.Primitive {
 		AddItem(nil, 0, 1, false)
 }
 
+// resizeModal changes the size of content centered by modal.
+func resizeModal(frame tview.Primitive, width, height int) {
+	outer, ok := frame.(*tview.Flex)
+	if !ok {
+		return
+	}
+	inner, ok := outer.GetItem(1).(*tview.Flex)
+	if !ok {
+		return
+	}
+	outer.ResizeItem(inner, width, 0)
+	inner.ResizeItem(inner.GetItem(1), height, 0)
+}
+
 // --- Syntax highlighting ---
 
-func highlightCode(code, language string) string {
+var (
+	highlightMu sync.Mutex
+	lexerCache  = map[string]chroma.Lexer{}
+	cachedStyle *chroma.Style
+)
+
+// cachedLexer returns the coalesced lexer for language, building it on first use.
+func cachedLexer(language string) chroma.Lexer {
+	highlightMu.Lock()
+	defer highlightMu.Unlock()
+
+	if lexer, ok := lexerCache[language]; ok {
+		return lexer
+	}
 	lexer := lexers.Get(language)
 	if lexer == nil {
 		lexer = lexers.Fallback
 	}
 	lexer = chroma.Coalesce(lexer)
+	lexerCache[language] = lexer
+	return lexer
+}
+
+// previewStyle returns the chroma style used for previews, resolving it once.
+func previewStyle() *chroma.Style {
+	highlightMu.Lock()
+	defer highlightMu.Unlock()
+
+	if cachedStyle == nil {
+		cachedStyle = styles.Get("gruvbox")
+		if cachedStyle == nil {
+			cachedStyle = styles.Fallback
+		}
+	}
+	return cachedStyle
+}
+
+// previewBackground returns the syntax theme's background color when
+// theme.background is enabled, and the terminal default otherwise.
+func (a *App) previewBackground() tcell.Color {
+	if !a.theme.Background || a.noColor {
+		return tcell.ColorDefault
+	}
+	bg := previewStyle().Get(chroma.Background).Background
+	if !bg.IsSet() {
+		return tcell.ColorDefault
+	}
+	return tcell.NewRGBColor(int32(bg.Red()), int32(bg.Green()), int32(bg.Blue()))
+}
+
+func (a *App) highlightCode(code, language string) string {
+	highlighted, _, _ := a.highlightCodeSearch(code, language, nil)
+	return highlighted
+}
+
+// highlightCodeSearch highlights code and wraps every match of re in a region
+// tag ("match-0", "match-1", ...) with a subtle background. It returns the
+// tagged text and the number of matches. If the lexer fails, code is
+// returned uncolored along with the lexer's error.
+func (a *App) highlightCodeSearch(code, language string, re *regexp.Regexp) (string, int, error) {
+	lexer := cachedLexer(language)
+	style := previewStyle()
 
-	style := styles.Get("gruvbox")
-	if style == nil {
-		style = styles.Fallback
+	// Region boundaries in code order; matches never overlap.
+	type boundary struct {
+		pos  int
+		open bool
+		id   int
+	}
+	var boundaries []boundary
+	if re != nil {
+		for _, m := range re.FindAllStringIndex(code, -1) {
+			if m[1] > m[0] {
+				id := len(boundaries) / 2
+				boundaries = append(boundaries, boundary{m[0], true, id}, boundary{m[1], false, id})
+			}
+		}
+	}
+	writeBoundary := func(buf *strings.Builder, b boundary) {
+		if b.open {
+			fmt.Fprintf(buf, `["match-%d"][:#504945]`, b.id)
+		} else {
+			buf.WriteString(`[:-][""]`)
+		}
 	}
 
 	var buf strings.Builder
+	tokens := []chroma.Token{{Type: chroma.Text, Value: code}}
+	var lexErr error
+	if !a.noColor {
+		if lexed, err := tokenise(lexer, code); err != nil {
+			lexErr = err // show the text uncolored rather than nothing
+		} else {
+			tokens = lexed
+		}
+	}
+
+	pos, next := 0, 0
+	for _, token := range tokens {
+		tag := ""
+		if !a.noColor {
+			tag = styleTag(style.Get(token.Type))
+		}
+
+		value := token.Value
+		end := pos + len(value)
+		for next < len(boundaries) && boundaries[next].pos < end {
+			b := boundaries[next]
+			writeToken(&buf, tag, value[:b.pos-pos])
+			writeBoundary(&buf, b)
+			value = value[b.pos-pos:]
+			pos = b.pos
+			next++
+		}
+		writeToken(&buf, tag, value)
+		pos = end
+	}
+	for ; next < len(boundaries); next++ {
+		writeBoundary(&buf, boundaries[next])
+	}
+	return buf.String(), len(boundaries) / 2, lexErr
+}
+
+// tokenise runs lexer over code, turning a panic inside chroma into an error
+// so pathological input can't take down the whole TUI.
+func tokenise(lexer chroma.Lexer, code string) (tokens []chroma.Token, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("lexer panicked: %v", r)
+		}
+	}()
 	iterator, err := lexer.Tokenise(nil, code)
 	if err != nil {
-		return tview.Escape(code)
+		return nil, err
 	}
+	return iterator.Tokens(), nil
+}
 
-	for token := iterator(); token != chroma.EOF; token = iterator() {
-		entry := style.Get(token.Type)
-		text := tview.Escape(token.Value)
-		if entry.Colour.IsSet() {
-			r, g, b := entry.Colour.Red(), entry.Colour.Green(), entry.Colour.Blue()
-			buf.WriteString(fmt.Sprintf("[#%02x%02x%02x]%s[-]", r, g, b, text))
-		} else {
-			buf.WriteString(text)
+// styleTag converts a chroma style entry into a tview style tag body
+// ("fg::attrs"), or "" if the entry sets neither color nor attributes.
+func styleTag(entry chroma.StyleEntry) string {
+	fg := ""
+	if entry.Colour.IsSet() {
+		fg = fmt.Sprintf("#%02x%02x%02x", entry.Colour.Red(), entry.Colour.Green(), entry.Colour.Blue())
+	}
+	attrs := ""
+	if entry.Bold == chroma.Yes {
+		attrs += "b"
+	}
+	if entry.Italic == chroma.Yes {
+		attrs += "i"
+	}
+	if entry.Underline == chroma.Yes {
+		attrs += "u"
+	}
+	if attrs == "" {
+		return fg
+	}
+	return fg + "::" + attrs
+}
+
+// writeToken writes escaped text, wrapped in a style tag when tag is set.
+// The closing [-::-] resets foreground and attributes but leaves the
+// background alone, so search-match highlighting survives across tokens.
+// Styled text is wrapped line by line so each output line is self-contained.
+func writeToken(buf *strings.Builder, tag, text string) {
+	if text == "" {
+		return
+	}
+	if tag == "" {
+		buf.WriteString(tview.Escape(text))
+		return
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		if line != "" {
+			fmt.Fprintf(buf, "[%s]%s[-::-]", tag, tview.Escape(line))
+		}
+	}
+}
+
+// withLineNumbers prefixes each line of tagged text with a gutter number.
+func withLineNumbers(text string) string {
+	lines := strings.Split(text, "\n")
+	width := len(strconv.Itoa(len(lines)))
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteByte('\n')
 		}
+		fmt.Fprintf(&b, "[darkgray]%*d[-] %s", width, i+1, line)
 	}
-	return buf.String()
+	return b.String()
 }
 
 func detectLanguage(filename string) string {
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestTitleCase(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"ui_helpers", "UI Helpers"},
+		{"mcp-servers", "MCP Servers"},
+		{"agents", "Agents"},
+		{"", ""},
+		{"-_-", "-_-"},
+	}
+	for _, tt := range tests {
+		if got := titleCase(tt.name, defaultAcronyms); got != tt.want {
+			t.Errorf("titleCase(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// newTestApp returns an App over a store holding the named commands, with
+// its project's claude_dir inside dir. The UI is set up but not run.
+func newTestApp(t *testing.T, dir string, commands ...string) *App {
+	t.Helper()
+	store := filepath.Join(dir, "store")
+	claudeDir := filepath.Join(dir, "project", ".claude")
+	for _, path := range []string{filepath.Join(store, "commands"), filepath.Join(claudeDir, "commands")} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range commands {
+		if err := os.WriteFile(filepath.Join(store, "commands", name), []byte("# "+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := &App{globalRoots: []string{store}, claudeDir: claudeDir, ascending: true}
+	if err := a.loadCategories(); err != nil {
+		t.Fatal(err)
+	}
+	a.setupUI()
+	a.buildKeymap(nil)
+	a.refreshAll()
+	return a
+}
+
+func TestRemoveAllKeepsStrayLinks(t *testing.T) {
+	dir := t.TempDir()
+	a := newTestApp(t, dir, "alpha.md", "beta.md")
+	cat := a.categories[a.activeTabIdx]
+
+	// alpha is applied; beta's project link points somewhere else.
+	applied := filepath.Join(cat.ProjectDir, "alpha.md")
+	if err := os.Symlink(filepath.Join(cat.GlobalDirs[0].Path, "alpha.md"), applied); err != nil {
+		t.Fatal(err)
+	}
+	elsewhere := filepath.Join(dir, "beta.md")
+	if err := os.WriteFile(elsewhere, []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stray := filepath.Join(cat.ProjectDir, "beta.md")
+	if err := os.Symlink(elsewhere, stray); err != nil {
+		t.Fatal(err)
+	}
+	a.refreshAll()
+	if len(a.appliedItems) != 2 {
+		t.Fatalf("applied = %d items, want alpha and the stray beta", len(a.appliedItems))
+	}
+
+	a.removeAll()
+	if _, err := os.Lstat(applied); !os.IsNotExist(err) {
+		t.Errorf("alpha.md was not removed: %v", err)
+	}
+	if _, err := os.Lstat(stray); err != nil {
+		t.Errorf("stray link was removed: %v", err)
+	}
+}
+
+func TestHandleCount(t *testing.T) {
+	names := make([]string, 10)
+	for i := range names {
+		names[i] = fmt.Sprintf("cmd%d.md", i)
+	}
+	press := func(a *App, keys string) (handled bool) {
+		for _, r := range keys {
+			handled = a.handleCount(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+		return handled
+	}
+
+	tests := []struct {
+		name      string
+		keys      string
+		handled   bool
+		wantRow   int // cursor row in Available
+		wantPanel int
+	}{
+		{"count moves down", "5j", true, 5, 0},
+		{"count moves up", "5j2k", true, 3, 0},
+		{"count jumps to item n", "3g", true, 2, 0},
+		{"count past the end jumps to the last item", "40g", true, 9, 0},
+		{"count before a key that takes none is dropped", "4J", false, 0, 0},
+		{"lone bound digit still runs its action", "2J", false, 0, 1},
+	}
+	for _, tt := range tests {
+		a := newTestApp(t, t.TempDir(), names...)
+		if got := press(a, tt.keys); got != tt.handled {
+			t.Errorf("%s: %q handled = %v, want %v", tt.name, tt.keys, got, tt.handled)
+		}
+		if got := a.availableList.GetCurrentItem(); got != tt.wantRow {
+			t.Errorf("%s: %q cursor = %d, want %d", tt.name, tt.keys, got, tt.wantRow)
+		}
+		if a.currentPanelIdx != tt.wantPanel {
+			t.Errorf("%s: %q panel = %d, want %d", tt.name, tt.keys, a.currentPanelIdx, tt.wantPanel)
+		}
+		if a.count != 0 {
+			t.Errorf("%s: %q left count %d pending", tt.name, tt.keys, a.count)
+		}
+	}
+}